@@ -0,0 +1,80 @@
+package solitaire
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// binaryHeaderLength is the number of letters encoding the length of the data.
+const binaryHeaderLength = 8
+
+// encodeBinary encodes each byte as a digraph of letters, the first letter
+// being the byte divided by 26 (A to J) and the second the remainder (A to Z).
+// The letters are preceded by the length of the data as four bytes, big
+// endian, encoded the same way.
+func encodeBinary(data []byte) []byte {
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(data)))
+	letters := make([]byte, 0, binaryHeaderLength+2*len(data))
+	for _, b := range append(header[:], data...) {
+		letters = append(letters, 'A'+b/26, 'A'+b%26)
+	}
+	return letters
+}
+
+// decodeBinary reverses encodeBinary. Letters after the data, like the
+// padding, are ignored.
+func decodeBinary(letters []byte) ([]byte, error) {
+	if len(letters) < binaryHeaderLength {
+		return nil, errors.New("binary ciphertext is too short for the length header")
+	}
+	decode := func(letters []byte) ([]byte, error) {
+		data := make([]byte, len(letters)/2)
+		for i := range data {
+			hi, lo := int(letters[2*i])-'A', int(letters[2*i+1])-'A'
+			if hi < 0 || hi > 25 || lo < 0 || lo > 25 || 26*hi+lo > math.MaxUint8 {
+				return nil, fmt.Errorf("invalid digraph %q at letter %d", letters[2*i:2*i+2], 2*i+1)
+			}
+			data[i] = byte(26*hi + lo)
+		}
+		return data, nil
+	}
+	header, err := decode(letters[:binaryHeaderLength])
+	if err != nil {
+		return nil, fmt.Errorf("invalid length header, wrong key? %w", err)
+	}
+	n := int(binary.BigEndian.Uint32(header))
+	if n > (len(letters)-binaryHeaderLength)/2 {
+		return nil, fmt.Errorf("length header of %d bytes exceeds the ciphertext, wrong key?", n)
+	}
+	return decode(letters[binaryHeaderLength : binaryHeaderLength+2*n])
+}
+
+// EncryptBinary encrypts arbitrary bytes, e.g. the content of a file.
+// Each byte is encoded as two letters, preceded by a header of eight letters
+// holding the length of the data, so that the padding to a multiple of five
+// letters is removed exactly by DecryptBinary. The letters are encrypted like
+// a cleartext, including the alphabet key and transpositions, if any.
+func (s *solitaire) EncryptBinary(data []byte) ([]byte, error) {
+	if len(data) > math.MaxUint32 {
+		return nil, errors.New("data must be smaller than 4 GiB")
+	}
+	return BlocksOfFive(s.addHeader(s.encryptLetters(padClearText(encodeBinary(data))))), nil
+}
+
+// DecryptBinary decrypts a ciphertext produced by EncryptBinary and returns
+// the exact bytes. A wrong key usually results in an invalid length header
+// or invalid digraphs, which are returned as errors.
+func (s *solitaire) DecryptBinary(ciphertext []byte) ([]byte, error) {
+	cleaned := normalizeCleartext(ciphertext)
+	if len(cleaned) == 0 || len(cleaned)%5 != 0 {
+		return nil, errors.New("ciphertext must be a non-empty multiple of 5 letters")
+	}
+	cleaned, err := s.checkHeader(cleaned)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBinary(s.decryptLetters(cleaned))
+}
@@ -0,0 +1,81 @@
+package solitaire
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BinarySuite struct {
+	suite.Suite
+}
+
+func (s *BinarySuite) TestEncodeBinary() {
+	s.Equal("AAAAAAADAAJVDG", string(encodeBinary([]byte{0, 255, 84})))
+	data, err := decodeBinary([]byte("AAAAAAADAAJVDGXX"))
+	s.NoError(err)
+	s.Equal([]byte{0, 255, 84}, data)
+}
+
+func (s *BinarySuite) TestDecodeBinaryInvalid() {
+	for _, letters := range []string{"AAAAAA", "AAAAAAAD", "AAAAAAACJWAA", "ZZAAAAACAAAA"} {
+		_, err := decodeBinary([]byte(letters))
+		s.Error(err, letters)
+	}
+}
+
+func (s *BinarySuite) TestRoundTrip() {
+	all := make([]byte, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	testCases := []struct {
+		desc string
+		data []byte
+	}{
+		{desc: "empty", data: []byte{}},
+		{desc: "all bytes", data: all},
+		{desc: "trailing padding letters", data: []byte("XXX\x00")},
+		{desc: "text", data: []byte("Hello, World!\n")},
+	}
+	for _, tC := range testCases {
+		s.Run(tC.desc, func() {
+			opts := []SolitaireOption{
+				WithPassphrase([]byte("CRYPTONOMICON")),
+				WithAlphabetKey([]byte("KEYWORD")),
+				WithDoubleTransposition([]byte("ZEBRAS"), []byte("WINDOW")),
+			}
+			sol, err := New(opts...)
+			s.Require().NoError(err)
+			ct, err := sol.EncryptBinary(tC.data)
+			s.Require().NoError(err)
+			s.Zero(len(normalizeCleartext(ct)) % 5)
+
+			sol, err = New(opts...)
+			s.Require().NoError(err)
+			data, err := sol.DecryptBinary(ct)
+			s.Require().NoError(err)
+			s.True(bytes.Equal(tC.data, data))
+		})
+	}
+}
+
+func (s *BinarySuite) TestWrongKey() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	ct, err := sol.EncryptBinary([]byte("secret"))
+	s.Require().NoError(err)
+
+	sol, err = New(WithPassphrase([]byte("WRONG")))
+	s.Require().NoError(err)
+	_, err = sol.DecryptBinary(ct)
+	s.Error(err)
+
+	_, err = sol.DecryptBinary([]byte("ABCD"))
+	s.Error(err)
+}
+
+func TestBinary(t *testing.T) {
+	suite.Run(t, new(BinarySuite))
+}
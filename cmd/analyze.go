@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/mwmahlberg/solitaire"
+)
+
+type analyzeCmd struct {
+	Cycle     analyzeCycleCmd     `kong:"cmd,help='Find the cycle length and pre-period of the deck'"`
+	Keystream analyzeKeystreamCmd `kong:"cmd,help='Run statistical tests on the keystreams of random keys'"`
+	Depth     analyzeDepthCmd     `kong:"cmd,help='Find ciphertexts that were encrypted with the same keystream'"`
+	Recover   analyzeRecoverCmd   `kong:"cmd,help='Experimental: search for deck states producing a known keystream'"`
+}
+
+type analyzeCycleCmd struct {
+	Limit  int `kong:"default='10000000',help='maximum number of deck advances to search for a cycle'"`
+	Budget int `kong:"default='100000',help='flag the deck as weak if its keystream repeats within this many advances'"`
+}
+
+func (a *analyzeCycleCmd) Run(ctx *kong.Context) error {
+	d, err := deckFromFlags()
+	if err != nil {
+		return err
+	}
+	info := solitaire.FindCycle(d, a.Limit)
+	if !info.Found {
+		fmt.Printf("no cycle found within %d advances\n", a.Limit)
+	} else {
+		fmt.Printf("cycle length: %d\n", info.Length)
+		fmt.Printf("pre-period:   %d\n", info.PrePeriod)
+	}
+	fmt.Printf("advances:     %d\n", info.Steps)
+	// The weak key check searches on its own, so that it agrees with
+	// print-deck --check-weak whatever --limit is.
+	if weak, _ := solitaire.IsWeakKey(d, a.Budget); weak {
+		warnf(ctx, "WARN: weak key, the keystream repeats within the budget of %d advances", a.Budget)
+	}
+	return nil
+}
+
+type analyzeKeystreamCmd struct {
+	Keys   int    `kong:"default='100',help='number of random passphrases to generate keystreams for'"`
+	Values int    `kong:"default='10000',help='number of keystream values per key'"`
+	Length int    `kong:"default='20',help='length of the random passphrases'"`
+	Seed   uint64 `kong:"default='1',help='seed for the random passphrases, to make runs reproducible'"`
+	Format string `kong:"default='table',enum='table,json',help='output format (${enum})'"`
+}
+
+func (a *analyzeKeystreamCmd) Validate() error {
+	// Run allocates the decks and passphrases up front.
+	switch {
+	case a.Keys <= 0:
+		return errors.New("--keys must be positive")
+	case a.Values <= 0:
+		return errors.New("--values must be positive")
+	case a.Length <= 0:
+		return errors.New("--length must be positive")
+	}
+	return nil
+}
+
+func (a *analyzeKeystreamCmd) Run() error {
+	rng := rand.New(rand.NewPCG(a.Seed, 0))
+	decks := make([]solitaire.Deck, a.Keys)
+	passphrase := make([]byte, a.Length)
+	for i := range decks {
+		for j := range passphrase {
+			passphrase[j] = byte('A' + rng.IntN(26))
+		}
+		s, err := solitaire.New(solitaire.WithPassphrase(passphrase))
+		if err != nil {
+			return err
+		}
+		copy(decks[i][:], s.Deck())
+	}
+
+	stats := solitaire.AnalyzeKeystream(decks, a.Values)
+	if a.Format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "keys\t%d\n", stats.Keys)
+	fmt.Fprintf(w, "values\t%d\n", stats.Values)
+	fmt.Fprintf(w, "chi-square (25 df)\t%.2f\tp=%.4f\n", stats.ChiSquare, stats.ChiSquareP)
+	fmt.Fprintf(w, "repeat rate\t%.5f\texpected %.5f, z=%.2f\n", stats.RepeatRate, stats.ExpectedRepeatRate, stats.RepeatZ)
+	fmt.Fprintf(w, "serial correlation\t%.5f\t\n", stats.SerialCorrelation)
+	fmt.Fprintf(w, "runs\t%d\texpected %.1f, z=%.2f\n", stats.Runs, stats.ExpectedRuns, stats.RunsZ)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("letter frequencies:")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	expected := float64(stats.Values) / 26
+	for i, f := range stats.Frequencies {
+		fmt.Fprintf(w, "%c\t%d\t%+.2f%%\n", 'A'+i, f, 100*(float64(f)-expected)/expected)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("digrams with the largest deviation from uniform:")
+	type digram struct {
+		first, second int
+		count         int
+	}
+	digrams := make([]digram, 0, 26*26)
+	pairs := 0
+	for i, row := range stats.Digrams {
+		for j, c := range row {
+			digrams = append(digrams, digram{i, j, c})
+			pairs += c
+		}
+	}
+	expected = float64(pairs) / (26 * 26)
+	deviation := func(d digram) float64 {
+		return (float64(d.count) - expected) / expected
+	}
+	sort.Slice(digrams, func(i, j int) bool {
+		return math.Abs(deviation(digrams[i])) > math.Abs(deviation(digrams[j]))
+	})
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, d := range digrams[:min(10, len(digrams))] {
+		fmt.Fprintf(w, "%c%c\t%d\t%+.2f%%\n", 'A'+d.first, 'A'+d.second, d.count, 100*deviation(d))
+	}
+	return w.Flush()
+}
+
+type analyzeDepthCmd struct {
+	MinOverlap int      `kong:"default='50',help='minimum number of overlapping letters for an alignment'"`
+	Threshold  float64  `kong:"default='4',help='z-score of the coincidence rate from which an alignment is flagged'"`
+	Files      []string `kong:"arg,type='existingfile',help='Files containing one ciphertext each'"`
+}
+
+func (a *analyzeDepthCmd) Run() error {
+	if len(a.Files) < 2 {
+		return fmt.Errorf("at least two ciphertexts are required")
+	}
+	cts := make([][]byte, len(a.Files))
+	for i, f := range a.Files {
+		ct, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		cts[i] = ct
+	}
+
+	matches := solitaire.FindDepth(cts, a.MinOverlap, a.Threshold)
+	if len(matches) == 0 {
+		fmt.Println("no ciphertexts in depth found")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "first\tsecond\toffset\toverlap\tIoC\tIoC p\tkappa\tkappa z")
+	for _, m := range matches {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%.4f\t%.4f\t%.4f\t%.2f\n",
+			filepath.Base(a.Files[m.First]), filepath.Base(a.Files[m.Second]),
+			m.Offset, m.Overlap, m.IoC, m.IoCP, m.Kappa, m.KappaZ)
+	}
+	return w.Flush()
+}
+
+type analyzeRecoverCmd struct {
+	Plaintext   string        `kong:"required,help='Known plaintext'"`
+	Ciphertext  string        `kong:"required,help='Ciphertext of the known plaintext, starting at the same position'"`
+	Known       string        `kong:"help='Cards known in advance, as printed by print-deck --export with ?? for unknown cards'"`
+	Timeout     time.Duration `kong:"default='1m',help='Stop the search after this duration'"`
+	MemoryLimit int           `kong:"default='256',help='Stop the search when the candidates use this many MiB'"`
+	Show        int           `kong:"default='10',help='Number of candidates to print'"`
+}
+
+func (a *analyzeRecoverCmd) Run() error {
+	keystream, err := solitaire.KeystreamFromPair([]byte(a.Plaintext), []byte(a.Ciphertext))
+	if err != nil {
+		return err
+	}
+	rc := solitaire.RecoverConfig{MemoryLimit: a.MemoryLimit << 20}
+	if a.Known != "" {
+		known, err := solitaire.ParsePartialDeck(a.Known)
+		if err != nil {
+			return err
+		}
+		rc.Known = *known
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.Timeout)
+	defer cancel()
+	start := time.Now()
+	result, err := solitaire.RecoverDeck(ctx, keystream, rc)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		fmt.Println("search stopped: time limit reached")
+	case err != nil && result != nil:
+		fmt.Printf("search stopped: %s\n", err)
+	case err != nil:
+		return err
+	}
+
+	fmt.Printf("keystream values: %d\n", len(keystream))
+	fmt.Printf("unknown cards:    %d\n", rc.Known.Unknown())
+	fmt.Printf("search nodes:     %d in %s\n", result.Nodes, time.Since(start).Round(time.Millisecond))
+	fmt.Printf("complete search:  %t\n", result.Complete)
+	fmt.Printf("candidates:       %d\n", len(result.Candidates))
+	for i, c := range result.Candidates {
+		if i >= a.Show {
+			fmt.Printf("... %d more\n", len(result.Candidates)-a.Show)
+			break
+		}
+		fmt.Printf("%s (%d unknown)\n", c.String(), c.Unknown())
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/awnumar/memguard"
+	"github.com/mwmahlberg/solitaire"
+)
+
+type codebookCmd struct {
+	Check codebookCheckCmd `kong:"cmd,help='Find ambiguous or overlapping codes in a codebook'"`
+}
+
+type codebookCheckCmd struct {
+	Dictionary map[string]string `kong:"placeholder='NAME=FILE',help='Additional word list to find codes occurring in words, one WORD COUNT per line'"`
+	File       string            `kong:"arg,type='existingfile',help='Codebook as CSV with phrase,code records or as YAML mapping phrases to codes'"`
+}
+
+func (c *codebookCheckCmd) Run() error {
+	for name, file := range c.Dictionary {
+		if err := registerDictionary(name, file); err != nil {
+			return err
+		}
+	}
+	cb, err := loadCodebook(c.File)
+	if err != nil {
+		return err
+	}
+	issues := cb.Check()
+	for _, issue := range issues {
+		fmt.Printf("%s: %s\n", issue.Kind, issue)
+	}
+	if len(issues) > 0 {
+		fmt.Printf("%d issues in %d codebook entries\n", len(issues), len(cb.Entries()))
+		memguard.SafeExit(1)
+	}
+	fmt.Printf("no issues in %d codebook entries\n", len(cb.Entries()))
+	return nil
+}
+
+// loadCodebook reads a codebook from a YAML file if its extension is .yaml or
+// .yml, and from a CSV file otherwise.
+func loadCodebook(file string) (*solitaire.Codebook, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var cb *solitaire.Codebook
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		cb, err = solitaire.LoadCodebookYAML(f)
+	default:
+		cb, err = solitaire.LoadCodebookCSV(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("codebook %s: %w", file, err)
+	}
+	return cb, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"time"
+
+	"github.com/mwmahlberg/solitaire"
+)
+
+type crackCmd struct {
+	Crib       string        `kong:"required,help='Known plaintext contained in the message'"`
+	Offset     int           `kong:"default='-1',help='Position of the crib in the plaintext letters, -1 if unknown'"`
+	Wordlist   string        `kong:"type='existingfile',xor='source',help='File with one passphrase candidate per line'"`
+	Combine    int           `kong:"default='1',help='Combine up to this many words from the wordlist'"`
+	KeepCase   bool          `kong:"help='Do not upper-case the words from the wordlist'"`
+	Mask       string        `kong:"xor='source',help='Mask for the candidates, ?u is any letter from A to Z, e.g. SECRET?u?u'"`
+	Workers    int           `kong:"help='Number of workers, defaults to the number of CPUs'"`
+	MaxMatches int           `kong:"default='1',help='Stop after this many matches, 0 tries all candidates'"`
+	Timeout    time.Duration `kong:"help='Stop after this duration, e.g. 10m'"`
+	Ciphertext []byte        `kong:"arg,type='filecontent',help='Ciphertext to be attacked',sep=''"`
+}
+
+func (c *crackCmd) Run() error {
+	var candidates iter.Seq[[]byte]
+	switch {
+	case c.Wordlist != "":
+		words, err := c.readWordlist()
+		if err != nil {
+			return err
+		}
+		candidates = solitaire.CombinationCandidates(words, c.Combine)
+	case c.Mask != "":
+		var err error
+		if candidates, err = solitaire.MaskCandidates(c.Mask); err != nil {
+			return err
+		}
+	default:
+		return errors.New("either --wordlist or --mask is required")
+	}
+
+	ctx := context.Background()
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	matches, err := solitaire.Crack(ctx, solitaire.CrackConfig{
+		Ciphertext: c.Ciphertext,
+		Crib:       []byte(c.Crib),
+		Offset:     c.Offset,
+		Workers:    c.Workers,
+		MaxMatches: c.MaxMatches,
+		Progress: func(p solitaire.CrackProgress) {
+			fmt.Fprintf(os.Stderr, "\rtried %d candidates in %s (%.0f/s), %d matches",
+				p.Tried, p.Elapsed.Round(time.Second), p.Rate(), p.Matches)
+		},
+	}, candidates)
+	fmt.Fprintln(os.Stderr)
+	for _, m := range matches {
+		fmt.Printf("%s (crib at offset %d)\n", m.Passphrase, m.Offset)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		fmt.Fprintln(os.Stderr, "timeout reached, not all candidates were tried")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		fmt.Println("no matching passphrase found")
+	}
+	return nil
+}
+
+func (c *crackCmd) readWordlist() ([][]byte, error) {
+	f, err := os.Open(c.Wordlist)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	words := make([][]byte, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		w := bytes.TrimSpace(scanner.Bytes())
+		if len(w) == 0 {
+			continue
+		}
+		if !c.KeepCase {
+			w = bytes.ToUpper(w)
+		}
+		words = append(words, bytes.Clone(w))
+	}
+	return words, scanner.Err()
+}
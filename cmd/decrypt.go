@@ -1,21 +1,87 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
 
 	"github.com/awnumar/memguard"
 	"github.com/mwmahlberg/solitaire"
 )
 
+// minConfidence is the language model confidence below which decrypt warns
+// that the plaintext does not look like natural language.
+const minConfidence = 0.5
+
 type decryptCmd struct {
-	Ciphertext []byte `kong:"arg,type='filecontent',help='Ciphertext to be decrypted',sep=''"` //nolint:golint
+	LanguageModel     map[string]string `kong:"placeholder='NAME=FILE',help='Additional quadgram table to score the plaintext against, one QUAD COUNT per line'"`
+	Dictionary        map[string]string `kong:"placeholder='NAME=FILE',help='Additional word list to segment the plaintext with, one WORD COUNT per line'"`
+	Repair            bool              `kong:"xor='repair',help='Resynchronize the keystream after letters dropped or inserted in transmission'"`
+	Segment           bool              `kong:"xor='output',help='Split the plaintext into words and drop the padding'"`
+	PreserveFormat    bool              `kong:"xor='output,format,header,repair',help='Decrypt a ciphertext encrypted with encrypt --preserve-format'"`
+	Codebook          string            `kong:"xor='format,repair',type='existingfile',help='Codebook file whose codes are expanded into their phrases after decrypting'"`
+	Binary            bool              `kong:"xor='output,format,repair',help='Decrypt a ciphertext encrypted with encrypt --binary and write the bytes to stdout'"`
+	FingerprintHeader bool              `kong:"xor='header',help='Check and strip the fingerprint header prepended by encrypt --fingerprint-header'"`
+	MaxErrors         int               `kong:"default='3',help='Maximum number of transmission errors to repair'"`
+	Ciphertext        []byte            `kong:"arg,type='filecontent',help='Ciphertext to be decrypted',sep=''"` //nolint:golint
+}
+
+func (p *decryptCmd) Validate() error {
+	if p.MaxErrors < 0 {
+		return errors.New("--max-errors must not be negative")
+	}
+	return checkPreservedFormat(p.PreserveFormat)
 }
 
 func (p *decryptCmd) Run() error {
-	s, err := solitaire.New(solitaire.WithPassphraseFromEnclave(cfg.Passphrase.enc))
+	for name, file := range p.LanguageModel {
+		if err := registerLanguage(name, file); err != nil {
+			return err
+		}
+	}
+	for name, file := range p.Dictionary {
+		if err := registerDictionary(name, file); err != nil {
+			return err
+		}
+	}
+	opts, err := keyOptions()
+	if err != nil {
+		return err
+	}
+	if p.PreserveFormat {
+		warnPreservedFormat()
+		opts = append(opts, solitaire.WithPreservedFormat())
+	}
+	if p.FingerprintHeader {
+		opts = append(opts, solitaire.WithFingerprintHeader())
+	}
+	if p.Codebook != "" {
+		cb, err := loadCodebook(p.Codebook)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, solitaire.WithCodebook(cb))
+	}
+	s, err := solitaire.New(opts...)
 	if err != nil {
 		memguard.SafePanic(err)
 	}
+	if p.Repair {
+		r, err := s.Repair(p.Ciphertext, p.MaxErrors)
+		if err != nil {
+			return fmt.Errorf("cannot repair ciphertext: %w", err)
+		}
+		printRepair(r, p.Segment)
+		return nil
+	}
+	if p.Binary {
+		data, err := s.DecryptBinary(p.Ciphertext)
+		if err != nil {
+			return fmt.Errorf("cannot decrypt binary ciphertext: %w", err)
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
 	ct, err := s.Decrypt(p.Ciphertext)
 	if err != nil {
 		memguard.SafePanic(err)
@@ -24,6 +90,66 @@ func (p *decryptCmd) Run() error {
 		memguard.SafePanic("Plaintext is nil")
 	}
 	// Print the plaintext
-	fmt.Printf("%s\n", ct)
+	if p.Segment {
+		fmt.Println(solitaire.Segment(ct))
+	} else {
+		fmt.Printf("%s\n", ct)
+	}
+	printConfidence(solitaire.Score(ct))
+	return nil
+}
+
+// printRepair prints the repaired plaintext, split into words if segment is
+// set, and the errors assumed on stderr.
+func printRepair(r *solitaire.RepairResult, segment bool) {
+	if segment {
+		fmt.Println(solitaire.Segment(r.Plaintext))
+	} else {
+		fmt.Printf("%s\n", r.Plaintext)
+	}
+	for _, e := range r.Errors {
+		fmt.Fprintf(os.Stderr, "assumed %s letter at ciphertext letter %d\n", e.Kind, e.Position+1)
+	}
+	if !r.Repaired {
+		fmt.Fprintln(os.Stderr, "WARN: the plaintext could not be repaired completely, try a higher --max-errors")
+	}
+	printConfidence(r.Assessment)
+}
+
+func printConfidence(a solitaire.Assessment) {
+	if a.Quadgrams == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "confidence: %.2f (%s)\n", a.Confidence, a.Language)
+	if a.Confidence < minConfidence {
+		fmt.Fprintln(os.Stderr, "WARN: the plaintext does not look like natural language, check the passphrase and the ciphertext")
+	}
+}
+
+func registerLanguage(name, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	q, err := solitaire.LoadQuadgrams(f)
+	if err != nil {
+		return fmt.Errorf("language model %s: %w", name, err)
+	}
+	solitaire.RegisterLanguage(name, q)
+	return nil
+}
+
+func registerDictionary(name, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	d, err := solitaire.LoadDictionary(f)
+	if err != nil {
+		return fmt.Errorf("dictionary %s: %w", name, err)
+	}
+	solitaire.RegisterDictionary(name, d)
 	return nil
 }
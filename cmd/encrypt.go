@@ -1,22 +1,55 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
 
 	"github.com/awnumar/memguard"
 	"github.com/mwmahlberg/solitaire"
 )
 
 type encrypt struct {
-	Cleartext []byte `kong:"arg,type='filecontent',help='Cleartext to be encrypted',sep=''"`
+	PreserveFormat    bool   `kong:"xor='format,header',help='Encrypt only the letters, keeping case, spaces and punctuation. Leaks the structure of the cleartext'"`
+	Codebook          string `kong:"xor='format',type='existingfile',help='Codebook file whose phrases are replaced by their codes before encrypting'"`
+	FingerprintHeader bool   `kong:"xor='header',help='Prepend the fingerprint of the deck as the first group of the ciphertext. It is sent in clear, linking all messages under the deck and narrowing the deck down for an attacker'"`
+	Binary            bool   `kong:"xor='format',help='Encrypt arbitrary bytes, e.g. a file, decrypt with decrypt --binary'"`
+	Cleartext         []byte `kong:"arg,type='filecontent',help='Cleartext to be encrypted',sep=''"`
+}
+
+func (p *encrypt) Validate() error {
+	return checkPreservedFormat(p.PreserveFormat)
 }
 
 func (p *encrypt) Run() error {
-	s, err := solitaire.New(solitaire.WithPassphraseFromEnclave(cfg.Passphrase.enc))
+	opts, err := keyOptions()
+	if err != nil {
+		return err
+	}
+	if p.PreserveFormat {
+		warnPreservedFormat()
+		opts = append(opts, solitaire.WithPreservedFormat())
+	}
+	if p.FingerprintHeader {
+		opts = append(opts, solitaire.WithFingerprintHeader())
+	}
+	if p.Codebook != "" {
+		cb, err := loadCodebook(p.Codebook)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, solitaire.WithCodebook(cb))
+	}
+	s, err := solitaire.New(opts...)
 	if err != nil {
 		memguard.SafePanic(err)
 	}
-	ct, err := s.Encrypt([]byte(p.Cleartext))
+	var ct []byte
+	if p.Binary {
+		ct, err = s.EncryptBinary(p.Cleartext)
+	} else {
+		ct, err = s.Encrypt([]byte(p.Cleartext))
+	}
 	if err != nil {
 		memguard.SafePanic(err)
 	}
@@ -26,3 +59,17 @@ func (p *encrypt) Run() error {
 	fmt.Println(string(ct))
 	return nil
 }
+
+// checkPreservedFormat rejects the global flags that cannot be combined with
+// --preserve-format. The conflicts with flags of the command are declared as
+// xor groups, which kong only checks within a command.
+func checkPreservedFormat(preserveFormat bool) error {
+	if preserveFormat && cfg.TranspositionKeys.first != nil {
+		return errors.New("--preserve-format and --transposition-keys can't be used together")
+	}
+	return nil
+}
+
+func warnPreservedFormat() {
+	fmt.Fprintln(os.Stderr, "WARN: --preserve-format leaks word lengths, punctuation and case of the cleartext, use it for teaching and low-value notes only")
+}
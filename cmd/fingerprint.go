@@ -0,0 +1,16 @@
+package main
+
+import (
+	"fmt"
+)
+
+type fingerprintCmd struct{}
+
+func (f *fingerprintCmd) Run() error {
+	d, err := deckFromFlags()
+	if err != nil {
+		return err
+	}
+	fmt.Println(d.Fingerprint())
+	return nil
+}
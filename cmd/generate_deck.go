@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mwmahlberg/solitaire"
+)
+
+type generateDeckCmd struct {
+	Name  string `kong:"help='Store the deck under this name in the keyring given by --keyring'"`
+	Dice  int    `kong:"placeholder='SIDES',help='Shuffle with rolls of dice with this number of sides, e.g. 6, 10 or 20, instead of crypto/rand'"`
+	Rolls string `kong:"help='Rolls for --dice, to re-derive a deck without prompting; digits for dice with up to ten sides (0 is 10), numbers separated by spaces otherwise'"`
+
+	// deck is derived from Rolls by Validate.
+	deck *solitaire.Deck
+}
+
+// Validate checks --dice and derives the deck from --rolls, so that too few
+// or invalid rolls are reported as a usage error.
+func (g *generateDeckCmd) Validate() error {
+	if g.Dice == 0 {
+		if g.Rolls != "" {
+			return errors.New("--rolls requires --dice")
+		}
+		return nil
+	}
+	if _, err := solitaire.NewDiceShuffle(g.Dice); err != nil {
+		return err
+	}
+	if g.Rolls == "" {
+		return nil
+	}
+	rolls, err := solitaire.ParseDiceRolls(g.Dice, g.Rolls)
+	if err != nil {
+		return fmt.Errorf("--rolls: %w", err)
+	}
+	if g.deck, err = solitaire.DeckFromDice(g.Dice, rolls); err != nil {
+		return fmt.Errorf("--rolls: %w", err)
+	}
+	return nil
+}
+
+func (g *generateDeckCmd) Run() error {
+	var k *solitaire.Keyring
+	if g.Name != "" {
+		// Open the keyring first, so that a wrong password is found before
+		// the deck is printed.
+		var err error
+		if k, err = openKeyring(true); err != nil {
+			return err
+		}
+	}
+	var d *solitaire.Deck
+	var err error
+	switch {
+	case g.deck != nil:
+		d = g.deck
+	case g.Dice != 0:
+		d, err = promptDice(g.Dice)
+	default:
+		d, err = solitaire.RandomDeck()
+	}
+	if err != nil {
+		return err
+	}
+	if k != nil {
+		if err := k.Add(g.Name, d); err != nil {
+			return err
+		}
+		if err := saveKeyring(k); err != nil {
+			return err
+		}
+	}
+
+	printDeck(*d)
+	fmt.Println()
+	fmt.Println(d.Export())
+	fmt.Println()
+	fmt.Println(string(solitaire.BlocksOfFive([]byte(d.LetterKey()))))
+	fmt.Println()
+	fmt.Println(strings.Join(d.Mnemonic(), " "))
+	fmt.Println()
+	if g.Dice == 0 {
+		fmt.Printf("entropy: %.1f bits (log2 54!)\n", solitaire.DeckEntropy)
+	}
+	if k != nil {
+		fmt.Printf("stored as %q in %s\n", g.Name, cfg.Keyring)
+	}
+	return nil
+}
+
+// promptDice reads rolls from stdin until the deck is shuffled, showing how
+// many rolls are still needed. At the end, it prints all rolls, so that the
+// deck can be re-derived with --rolls.
+func promptDice(sides int) (*solitaire.Deck, error) {
+	ds, err := solitaire.NewDiceShuffle(sides)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for !ds.Done() {
+		atLeast, expected := ds.Remaining()
+		fmt.Fprintf(os.Stderr, "d%d rolls, at least %d (about %.0f) more needed: ", sides, atLeast, expected)
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("input ended, at least %d more rolls are needed", atLeast)
+		}
+		rolls, err := solitaire.ParseDiceRolls(sides, scanner.Text())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s, the line was ignored\n", err)
+			continue
+		}
+		for i, r := range rolls {
+			if ds.Done() {
+				fmt.Fprintf(os.Stderr, "the deck is shuffled, the last %d rolls were ignored\n", len(rolls)-i)
+				break
+			}
+			if err := ds.Roll(r); err != nil {
+				return nil, err
+			}
+		}
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Printf("rolls: %s\n\n", solitaire.FormatDiceRolls(sides, ds.Rolls()))
+	return ds.Deck()
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mwmahlberg/solitaire"
+)
+
+type kdfParamsCmd struct {
+	Time    uint32 `kong:"default='3',help='Number of passes over the memory'"`
+	Memory  uint32 `kong:"default='65536',help='Memory in KiB'"`
+	Threads uint8  `kong:"default='4',help='Degree of parallelism'"`
+}
+
+func (k *kdfParamsCmd) Validate() error {
+	// Check the costs the way --kdf will.
+	p := solitaire.KDFParams{Salt: make([]byte, 16), Time: k.Time, Memory: k.Memory, Threads: k.Threads}
+	_, err := solitaire.ParseKDFParams(p.String())
+	return err
+}
+
+func (k *kdfParamsCmd) Run() error {
+	p, err := solitaire.NewKDFParams()
+	if err != nil {
+		return err
+	}
+	p.Time, p.Memory, p.Threads = k.Time, k.Memory, k.Threads
+	fmt.Println(p.String())
+	return nil
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/awnumar/memguard"
+	"github.com/mwmahlberg/solitaire"
+)
+
+// deckFlag holds a deck given in the format of print-deck --export or
+// print-deck --letter-key.
+type deckFlag struct {
+	enc *memguard.Enclave
+}
+
+func (d *deckFlag) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return nil
+	}
+	d.enc = memguard.NewEnclave(text)
+	return nil
+}
+
+func (d *deckFlag) Validate() error {
+	if d.enc == nil {
+		return errors.New("deck must not be empty")
+	}
+	_, err := d.open()
+	return err
+}
+
+func (d *deckFlag) open() (*solitaire.Deck, error) {
+	b, err := d.enc.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer b.Destroy()
+	if !bytes.ContainsRune(b.Bytes(), ',') {
+		return solitaire.ParseLetterKey(b.String())
+	}
+	return solitaire.ParseDeck(b.String())
+}
+
+// deckMnemonicFlag holds a deck given as the words of print-deck --mnemonic.
+type deckMnemonicFlag struct {
+	enc *memguard.Enclave
+}
+
+func (d *deckMnemonicFlag) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return nil
+	}
+	d.enc = memguard.NewEnclave(text)
+	return nil
+}
+
+func (d *deckMnemonicFlag) Validate() error {
+	if d.enc == nil {
+		return errors.New("deck mnemonic must not be empty")
+	}
+	_, err := d.open()
+	return err
+}
+
+func (d *deckMnemonicFlag) open() (*solitaire.Deck, error) {
+	b, err := d.enc.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer b.Destroy()
+	return solitaire.DeckFromMnemonic(strings.Fields(b.String()))
+}
+
+// hasLetters matches text that contains at least one letter.
+var hasLetters = regexp.MustCompile(`[a-zA-Z]`)
+
+// alphabetKeyFlag holds the keyword of a mixed alphabet.
+type alphabetKeyFlag struct {
+	enc *memguard.Enclave
+}
+
+func (a *alphabetKeyFlag) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return nil
+	}
+	a.enc = memguard.NewEnclave(text)
+	return nil
+}
+
+func (a *alphabetKeyFlag) Validate() error {
+	if a.enc == nil {
+		return errors.New("alphabet key must not be empty")
+	}
+	b, err := a.enc.Open()
+	if err != nil {
+		return err
+	}
+	defer b.Destroy()
+	if !hasLetters.Match(b.Bytes()) {
+		return errors.New("alphabet key must contain letters")
+	}
+	return nil
+}
+
+// transpositionFlag holds the two keywords of a double transposition,
+// given separated by a comma.
+type transpositionFlag struct {
+	first, second *memguard.Enclave
+}
+
+func (t *transpositionFlag) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return nil
+	}
+	first, second, ok := bytes.Cut(text, []byte(","))
+	if !ok {
+		return errors.New("transposition keys must be two keywords separated by a comma")
+	}
+	t.first, t.second = memguard.NewEnclave(first), memguard.NewEnclave(second)
+	return nil
+}
+
+func (t *transpositionFlag) Validate() error {
+	if t.first == nil || t.second == nil {
+		return errors.New("transposition keys must not be empty")
+	}
+	_, err := solitaire.New(solitaire.WithPassphrase(nil), t.option())
+	return err
+}
+
+func (t *transpositionFlag) option() solitaire.SolitaireOption {
+	return solitaire.WithDoubleTranspositionFromEnclaves(t.first, t.second)
+}
+
+// kdfFlag holds the parameters for keying the deck from the passphrase with
+// a key derivation function, as printed by kdf-params.
+type kdfFlag struct {
+	params *solitaire.KDFParams
+}
+
+func (k *kdfFlag) UnmarshalText(text []byte) error {
+	p, err := solitaire.ParseKDFParams(string(text))
+	if err != nil {
+		return err
+	}
+	k.params = &p
+	return nil
+}
+
+// keyringPasswordFlag holds the password of a keyring file.
+type keyringPasswordFlag struct {
+	enc *memguard.Enclave
+}
+
+func (k *keyringPasswordFlag) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return nil
+	}
+	k.enc = memguard.NewEnclave(text)
+	return nil
+}
+
+// openKeyring reads and decrypts the keyring file given by --keyring.
+// If the file does not exist and create is true, it returns an empty keyring.
+func openKeyring(create bool) (*solitaire.Keyring, error) {
+	if cfg.Keyring == "" {
+		return nil, errors.New("keyring is required, use --keyring")
+	}
+	if cfg.KeyringPassword.enc == nil {
+		return nil, errors.New("keyring password is required, use --keyring-password-prompt or another --keyring-password flag")
+	}
+	data, err := os.ReadFile(cfg.Keyring)
+	if create && errors.Is(err, fs.ErrNotExist) {
+		return solitaire.NewKeyring(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	b, err := cfg.KeyringPassword.enc.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer b.Destroy()
+	k, err := solitaire.OpenKeyring(data, b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("keyring %s: %w", cfg.Keyring, err)
+	}
+	return k, nil
+}
+
+// saveKeyring encrypts the keyring and replaces the file given by --keyring.
+// The file is only readable by the user.
+func saveKeyring(k *solitaire.Keyring) error {
+	b, err := cfg.KeyringPassword.enc.Open()
+	if err != nil {
+		return err
+	}
+	defer b.Destroy()
+	data, err := k.Seal(b.Bytes())
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(cfg.Keyring), ".keyring-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), cfg.Keyring)
+}
+
+// keyOptions returns the options to key a solitaire instance from the
+// global flags. An explicit deck, given directly or as a mnemonic, takes
+// precedence over a deck from the keyring, which takes precedence over a
+// passphrase.
+// An alphabet key and transposition keys are added to either.
+func keyOptions() ([]solitaire.SolitaireOption, error) {
+	var opts []solitaire.SolitaireOption
+	switch {
+	case cfg.Deck.enc != nil:
+		d, err := cfg.Deck.open()
+		if err != nil {
+			return nil, err
+		}
+		opts = []solitaire.SolitaireOption{solitaire.WithDeck(d)}
+	case cfg.DeckMnemonic.enc != nil:
+		d, err := cfg.DeckMnemonic.open()
+		if err != nil {
+			return nil, err
+		}
+		opts = []solitaire.SolitaireOption{solitaire.WithDeck(d)}
+	case cfg.KeyringDeck != "":
+		k, err := openKeyring(false)
+		if err != nil {
+			return nil, err
+		}
+		d, err := k.Deck(cfg.KeyringDeck)
+		if err != nil {
+			return nil, err
+		}
+		opts = []solitaire.SolitaireOption{solitaire.WithDeck(d)}
+	case cfg.Passphrase.enc != nil && cfg.KDF.params != nil:
+		opts = []solitaire.SolitaireOption{solitaire.WithKDFPassphraseFromEnclave(cfg.Passphrase.enc, *cfg.KDF.params)}
+	case cfg.Passphrase.enc != nil && cfg.NormalizePassphrase:
+		opts = []solitaire.SolitaireOption{solitaire.WithNormalizedPassphraseFromEnclave(cfg.Passphrase.enc)}
+	case cfg.Passphrase.enc != nil:
+		opts = []solitaire.SolitaireOption{solitaire.WithPassphraseFromEnclave(cfg.Passphrase.enc)}
+	default:
+		return nil, errors.New("passphrase or deck is required, use --passphrase-prompt or another --passphrase flag, --deck or --keyring-deck")
+	}
+	if cfg.AlphabetKey.enc != nil {
+		opts = append(opts, solitaire.WithAlphabetKeyFromEnclave(cfg.AlphabetKey.enc))
+	}
+	if cfg.TranspositionKeys.first != nil {
+		opts = append(opts, cfg.TranspositionKeys.option())
+	}
+	return opts, nil
+}
+
+// deckFromFlags returns the deck keyed from the global flags.
+func deckFromFlags() (solitaire.Deck, error) {
+	var d solitaire.Deck
+	opts, err := keyOptions()
+	if err != nil {
+		return d, err
+	}
+	s, err := solitaire.New(opts...)
+	if err != nil {
+		return d, err
+	}
+	copy(d[:], s.Deck())
+	return d, nil
+}
@@ -6,10 +6,37 @@ import (
 )
 
 var cfg struct {
-	Passphrase passphrase `kong:"help='Passphrase for the de- and encryption'"`
-	Encrypt    encrypt    `kong:"cmd,help='Encrypt the given cleartext'"`
-	Decrypt    decryptCmd `kong:"cmd,help='Decrypt the given ciphertext'"`
-	PrintDeck  PrintDeck  `kong:"cmd,help='Print the deck for a given passphrase'"`
+	Passphrase            passphrase                `kong:"xor='passphrase',help='Passphrase for the de- and encryption; it shows up in the shell history and the process list, prefer the other --passphrase flags'"`
+	PassphraseFile        passphraseFileFlag        `kong:"xor='passphrase',placeholder='PATH',help='Read the passphrase from a file'"`
+	PassphraseEnv         passphraseEnvFlag         `kong:"xor='passphrase',placeholder='NAME',help='Read the passphrase from an environment variable, which is then removed'"`
+	PassphraseStdin       passphraseStdinFlag       `kong:"xor='passphrase,stdin',help='Read the passphrase from the first line of stdin'"`
+	PassphrasePrompt      passphrasePromptFlag      `kong:"xor='passphrase',help='Prompt for the passphrase on the terminal without echoing it'"`
+	MinStrength           string                    `kong:"enum='weak,fair,good,strong,full',default='weak',help='Refuse passphrases whose estimated strength is below this level: weak, fair (64 bits), good (96), strong (128) or full (the 237 bits of a random deck)'"`
+	NormalizePassphrase   bool                      `kong:"xor='passphrase-mode',help='Key the deck with the letters of the passphrase normalized like a cleartext, so that a sentence with spaces, digits and umlauts can be used; the letters are shown on stderr'"`
+	KDF                   kdfFlag                   `kong:"name='kdf',xor='passphrase-mode',placeholder='PARAMS',help='Key the deck from the passphrase with Argon2id using the parameters printed by kdf-params; any UTF-8 passphrase is accepted, but the deck cannot be set up by hand'"`
+	Deck                  deckFlag                  `kong:"xor='deck',help='Deck as printed by print-deck --export or --letter-key, used instead of a passphrase'"`
+	DeckMnemonic          deckMnemonicFlag          `kong:"xor='deck',help='Deck as the words printed by print-deck --mnemonic, used instead of a passphrase'"`
+	Keyring               string                    `kong:"type='path',help='Keyring file holding named decks, see generate-deck'"`
+	KeyringPassword       keyringPasswordFlag       `kong:"xor='keyring-password',help='Password of the keyring file; it shows up in the shell history and the process list, prefer the other --keyring-password flags'"`
+	KeyringPasswordFile   keyringPasswordFileFlag   `kong:"xor='keyring-password',placeholder='PATH',help='Read the keyring password from a file'"`
+	KeyringPasswordEnv    keyringPasswordEnvFlag    `kong:"xor='keyring-password',placeholder='NAME',help='Read the keyring password from an environment variable, which is then removed'"`
+	KeyringPasswordStdin  keyringPasswordStdinFlag  `kong:"xor='keyring-password,stdin',help='Read the keyring password from the first line of stdin'"`
+	KeyringPasswordPrompt keyringPasswordPromptFlag `kong:"xor='keyring-password',help='Prompt for the keyring password on the terminal without echoing it'"`
+	KeyringDeck           string                    `kong:"placeholder='NAME',help='Name of the deck in the keyring to use instead of a passphrase'"`
+	TranspositionKeys     transpositionFlag         `kong:"placeholder='FIRST,SECOND',help='Keywords of a double columnar transposition applied on top of the encryption'"`
+	AlphabetKey           alphabetKeyFlag           `kong:"help='Keyword for a mixed alphabet used instead of A to Z when adding the keystream'"`
+	Encrypt               encrypt                   `kong:"cmd,help='Encrypt the given cleartext'"`
+	Decrypt               decryptCmd                `kong:"cmd,help='Decrypt the given ciphertext'"`
+	GenerateDeck          generateDeckCmd           `kong:"cmd,help='Generate a random deck with the full entropy of a shuffled deck'"`
+	Fingerprint           fingerprintCmd            `kong:"cmd,help='Print the fingerprint of the deck to confirm that both stations hold the same deck'"`
+	PassphraseCmd         passphraseCmd             `kong:"cmd,name='passphrase',help='Work with passphrases'"`
+	KDFParams             kdfParamsCmd              `kong:"cmd,name='kdf-params',help='Print new parameters with a random salt for --kdf'"`
+	Split                 splitCmd                  `kong:"cmd,help='Split the deck into shares, a threshold of which restores it'"`
+	Combine               combineCmd                `kong:"cmd,help='Restore a deck from the shares printed by split'"`
+	PrintDeck             PrintDeck                 `kong:"cmd,help='Print the deck for a given passphrase'"`
+	Analyze               analyzeCmd                `kong:"cmd,help='Analyze keys and keystreams'"`
+	Codebook              codebookCmd               `kong:"cmd,help='Work with codebooks of phrases and their codes'"`
+	Crack                 crackCmd                  `kong:"cmd,help='Search for the passphrase of a ciphertext with a known plaintext'"`
 }
 
 func main() {
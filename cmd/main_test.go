@@ -0,0 +1,55 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parse parses the command line like main, without running the command.
+func parse(t *testing.T, args ...string) error {
+	t.Helper()
+	parser, err := kong.New(&cfg, kong.Name("solitaire"), kong.Writers(io.Discard, io.Discard), kong.Exit(func(int) {}))
+	require.NoError(t, err)
+	_, err = parser.Parse(args)
+	return err
+}
+
+func TestPreservedFormatConflicts(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "text")
+	require.NoError(t, os.WriteFile(text, []byte("Hello, World!"), 0o600))
+	codebook := filepath.Join(dir, "codebook.csv")
+	require.NoError(t, os.WriteFile(codebook, []byte("HELLO WORLD,QHW\n"), 0o600))
+
+	for _, command := range []string{"encrypt", "decrypt"} {
+		assert.NoError(t, parse(t, command, "--preserve-format", text), command)
+
+		for _, tC := range []struct {
+			desc string
+			args []string
+		}{
+			{desc: "transposition", args: []string{"--transposition-keys=ZEBRA,KEY", command, "--preserve-format", text}},
+			{desc: "codebook", args: []string{command, "--preserve-format", "--codebook", codebook, text}},
+			{desc: "fingerprint header", args: []string{command, "--preserve-format", "--fingerprint-header", text}},
+		} {
+			t.Run(command+" "+tC.desc, func(t *testing.T) {
+				assert.ErrorContains(t, parse(t, tC.args...), "can't be used together")
+			})
+		}
+	}
+}
+
+func TestAnalyzeKeystreamCounts(t *testing.T) {
+	assert.NoError(t, parse(t, "analyze", "keystream"))
+	for _, flag := range []string{"--keys", "--values", "--length"} {
+		for _, value := range []string{"0", "-1"} {
+			assert.ErrorContains(t, parse(t, "analyze", "keystream", flag+"="+value), flag+" must be positive")
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/alecthomas/kong"
 	"github.com/awnumar/memguard"
+	"github.com/mwmahlberg/solitaire"
 )
 
 var isValidPassphrase = regexp.MustCompile(`^[a-zA-Z]+$`)
@@ -28,20 +29,130 @@ func (p *passphrase) Validate(ctx *kong.Context) error {
 	if p.enc == nil {
 		return errors.New("passphrase is required, use --passphrase. e.g. --passphrase=secret. If you really want to use an empty passphrase, use --passphrase=''")
 	}
-	b, err := p.enc.Open()
+	return validatePassphrase(ctx, p.enc)
+}
+
+// validatePassphrase checks the strength of the passphrase and, unless it is
+// stretched with --kdf, that it can key a deck.
+func validatePassphrase(ctx *kong.Context, enc *memguard.Enclave) error {
+	b, err := enc.Open()
 	if err != nil {
 		return fmt.Errorf("failed to open passphrase containr: %w", err)
 	}
 	defer b.Destroy()
 	if len(b.Bytes()) == 0 {
-		ctx.Printf("WARN: passphrase is empty, this is not recommended")
+		warnf(ctx, "WARN: passphrase is empty, this is not recommended")
+	}
+	if solitaire.IsLetterKey(b.Bytes()) {
+		return errors.New("passphrase is a letter key as printed by print-deck --letter-key, which keys a different deck; use --deck to set the deck from it")
 	}
-	if len(b.Bytes()) < 80 {
-		ctx.Printf("WARN: passphrase should be at least 80 characters long")
+	if cfg.NormalizePassphrase {
+		letters := solitaire.NormalizePassphrase(b.Bytes())
+		defer memguard.WipeBytes(letters)
+		if len(letters) == 0 {
+			return errors.New("passphrase must contain letters")
+		}
+		// Show the letters, so that a transliteration or a dropped
+		// character does not go unnoticed.
+		warnf(ctx, "passphrase letters: %s", letters)
+	}
+	strength := passphraseStrength(b.Bytes())
+	minLevel, err := solitaire.ParseStrengthLevel(cfg.MinStrength)
+	if err != nil {
+		return err
+	}
+	if strength.Level < minLevel {
+		return fmt.Errorf("passphrase is %s with an estimated %.0f bits, --min-strength requires %s (%.0f bits), see passphrase check", strength.Level, strength.Entropy, minLevel, minLevel.Bits())
+	}
+	if strength.Level < solitaire.StrengthStrong {
+		warnf(ctx, "WARN: passphrase is %s with an estimated %.0f bits, a random deck has %.0f bits, see passphrase check", strength.Level, strength.Entropy, solitaire.DeckEntropy)
+	}
+	if cfg.KDF.params != nil || cfg.NormalizePassphrase {
+		// The key derivation function and the normalization accept any passphrase.
+		return nil
 	}
 	// Check if the passphrase contains only alphanumeric characters
 	if !isValidPassphrase.Match(b.Bytes()) {
-		return errors.New("passphrase must contain only letters from A-Z and a-z")
+		return errors.New("passphrase must contain only letters from A-Z and a-z, use --normalize-passphrase or --kdf for other passphrases")
+	}
+	return nil
+}
+
+// passphraseStrength rates the passphrase the way the deck is keyed from it:
+// the raw text for --kdf, the normalized letters for --normalize-passphrase
+// and the bytes as they are otherwise.
+func passphraseStrength(passphrase []byte) solitaire.PassphraseStrength {
+	switch {
+	case cfg.KDF.params != nil:
+		return solitaire.EstimateTextStrength(passphrase)
+	case cfg.NormalizePassphrase:
+		letters := solitaire.NormalizePassphrase(passphrase)
+		defer memguard.WipeBytes(letters)
+		return solitaire.EstimateStrength(letters)
+	default:
+		return solitaire.EstimateStrength(passphrase)
+	}
+}
+
+// warnf prints a warning to stderr, so that it does not mix with the output,
+// e.g. the bytes written by decrypt --binary.
+func warnf(ctx *kong.Context, format string, args ...any) {
+	fmt.Fprintf(ctx.Stderr, "%s: %s\n", ctx.Model.Name, fmt.Sprintf(format, args...))
+}
+
+type passphraseCmd struct {
+	Check    passphraseCheckCmd    `kong:"cmd,help='Estimate the strength of the passphrase given by --passphrase'"`
+	Generate passphraseGenerateCmd `kong:"cmd,help='Generate a random passphrase for --passphrase'"`
+}
+
+type passphraseCheckCmd struct{}
+
+func (p *passphraseCheckCmd) Run() error {
+	if cfg.Passphrase.enc == nil {
+		return errors.New("passphrase is required, use --passphrase-prompt or another --passphrase flag")
+	}
+	b, err := cfg.Passphrase.enc.Open()
+	if err != nil {
+		return err
+	}
+	defer b.Destroy()
+	if cfg.NormalizePassphrase {
+		letters := solitaire.NormalizePassphrase(b.Bytes())
+		defer memguard.WipeBytes(letters)
+		fmt.Printf("letters:     %s\n", letters)
+	}
+	strength := passphraseStrength(b.Bytes())
+	fmt.Printf("entropy:     about %.1f bits\n", strength.Entropy)
+	fmt.Printf("random deck: %.1f bits, the passphrase reaches %.0f%%\n", solitaire.DeckEntropy, 100*strength.Deck())
+	fmt.Printf("level:       %s\n", strength.Level)
+	if len(strength.Weaknesses) > 0 {
+		fmt.Println("weaknesses:")
+		for _, w := range strength.Weaknesses {
+			fmt.Printf("  %s\n", w)
+		}
+	}
+	return nil
+}
+
+type passphraseGenerateCmd struct {
+	Mode string  `kong:"enum='letters,words,syllables',default='letters',help='Random letters, shown in groups of five, words of the BIP39 English word list or syllables of a consonant and a vowel'"`
+	Bits float64 `kong:"default='128',help='Entropy the passphrase must reach in bits; a random deck has 237'"`
+}
+
+func (p *passphraseGenerateCmd) Run() error {
+	mode, err := solitaire.ParsePassphraseMode(p.Mode)
+	if err != nil {
+		return err
+	}
+	g, err := solitaire.GeneratePassphrase(mode, p.Bits)
+	if err != nil {
+		return err
+	}
+	fmt.Println(g.Passphrase)
+	if mode == solitaire.PassphraseLetters {
+		fmt.Println(string(solitaire.BlocksOfFive([]byte(g.Passphrase))))
 	}
+	fmt.Println()
+	fmt.Printf("entropy: %.1f bits\n", g.Entropy)
 	return nil
 }
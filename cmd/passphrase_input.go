@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/awnumar/memguard"
+	"golang.org/x/term"
+)
+
+// The flags below read the passphrase and the keyring password from other
+// sources than the command line, where they would end up in the shell history
+// and the process list. Each of them sets the secret as if it had been given
+// with --passphrase or --keyring-password. They read it after the flags were
+// validated, so that nothing is read or prompted for if the command line is
+// invalid.
+
+// passphraseFileFlag reads the passphrase from a file.
+type passphraseFileFlag struct {
+	path string
+}
+
+func (p *passphraseFileFlag) UnmarshalText(text []byte) error {
+	p.path = string(text)
+	return nil
+}
+
+func (p *passphraseFileFlag) AfterApply(ctx *kong.Context) error {
+	buf, err := readSecretFile(ctx, p.path, "passphrase")
+	if err != nil {
+		return err
+	}
+	return setPassphrase(ctx, buf)
+}
+
+// passphraseEnvFlag reads the passphrase from an environment variable.
+type passphraseEnvFlag struct {
+	name string
+}
+
+func (p *passphraseEnvFlag) UnmarshalText(text []byte) error {
+	p.name = string(text)
+	return nil
+}
+
+func (p *passphraseEnvFlag) AfterApply(ctx *kong.Context) error {
+	buf, err := readSecretEnv(p.name)
+	if err != nil {
+		return err
+	}
+	return setPassphrase(ctx, buf)
+}
+
+// passphraseStdinFlag reads the passphrase from the first line of stdin.
+type passphraseStdinFlag bool
+
+func (p *passphraseStdinFlag) AfterApply(ctx *kong.Context) error {
+	if !*p {
+		return nil
+	}
+	buf, err := readSecretStdin("passphrase")
+	if err != nil {
+		return err
+	}
+	return setPassphrase(ctx, buf)
+}
+
+// passphrasePromptFlag prompts for the passphrase on the terminal without
+// echoing it, and asks to enter it again for confirmation.
+type passphrasePromptFlag bool
+
+func (p *passphrasePromptFlag) AfterApply(ctx *kong.Context) error {
+	if !*p {
+		return nil
+	}
+	buf, err := promptSecret("passphrase")
+	if err != nil {
+		return err
+	}
+	return setPassphrase(ctx, buf)
+}
+
+// keyringPasswordFileFlag reads the keyring password from a file.
+type keyringPasswordFileFlag struct {
+	path string
+}
+
+func (k *keyringPasswordFileFlag) UnmarshalText(text []byte) error {
+	k.path = string(text)
+	return nil
+}
+
+func (k *keyringPasswordFileFlag) AfterApply(ctx *kong.Context) error {
+	buf, err := readSecretFile(ctx, k.path, "keyring password")
+	if err != nil {
+		return err
+	}
+	return setKeyringPassword(buf)
+}
+
+// keyringPasswordEnvFlag reads the keyring password from an environment
+// variable.
+type keyringPasswordEnvFlag struct {
+	name string
+}
+
+func (k *keyringPasswordEnvFlag) UnmarshalText(text []byte) error {
+	k.name = string(text)
+	return nil
+}
+
+func (k *keyringPasswordEnvFlag) AfterApply() error {
+	buf, err := readSecretEnv(k.name)
+	if err != nil {
+		return err
+	}
+	return setKeyringPassword(buf)
+}
+
+// keyringPasswordStdinFlag reads the keyring password from the first line of
+// stdin.
+type keyringPasswordStdinFlag bool
+
+func (k *keyringPasswordStdinFlag) AfterApply() error {
+	if !*k {
+		return nil
+	}
+	buf, err := readSecretStdin("keyring password")
+	if err != nil {
+		return err
+	}
+	return setKeyringPassword(buf)
+}
+
+// keyringPasswordPromptFlag prompts for the keyring password on the terminal
+// without echoing it, and asks to enter it again for confirmation.
+type keyringPasswordPromptFlag bool
+
+func (k *keyringPasswordPromptFlag) AfterApply() error {
+	if !*k {
+		return nil
+	}
+	buf, err := promptSecret("keyring-password")
+	if err != nil {
+		return err
+	}
+	return setKeyringPassword(buf)
+}
+
+// readSecretFile reads the secret called what from the file at path.
+func readSecretFile(ctx *kong.Context, path, what string) (*memguard.LockedBuffer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if fi, err := f.Stat(); err == nil && fi.Mode().Perm()&0o077 != 0 {
+		warnf(ctx, "WARN: %s file %s is readable by others, use chmod 600", what, path)
+	}
+	buf, err := memguard.NewBufferFromEntireReader(f)
+	if err != nil {
+		buf.Destroy()
+		return nil, err
+	}
+	return buf, nil
+}
+
+// readSecretEnv reads a secret from the environment variable name.
+func readSecretEnv(name string) (*memguard.LockedBuffer, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s is not set", name)
+	}
+	// Remove the variable, so that it is not passed on to other processes.
+	// The string returned by the runtime cannot be wiped.
+	if err := os.Unsetenv(name); err != nil {
+		return nil, err
+	}
+	return memguard.NewBufferFromBytes([]byte(value)), nil
+}
+
+// readSecretStdin reads the secret called what from the first line of stdin.
+func readSecretStdin(what string) (*memguard.LockedBuffer, error) {
+	// The buffer is read byte by byte, so that the rest of stdin is left
+	// for the command, e.g. the rolls of generate-deck --dice.
+	buf, err := memguard.NewBufferFromReaderUntil(os.Stdin, '\n')
+	if err != nil && !(errors.Is(err, io.EOF) && buf.Size() > 0) {
+		buf.Destroy()
+		return nil, fmt.Errorf("failed to read %s from stdin: %w", what, err)
+	}
+	return buf, nil
+}
+
+// promptSecret prompts for the secret set by the flags starting with --flag,
+// e.g. passphrase, on the terminal without echoing it, and asks to enter it
+// again for confirmation.
+func promptSecret(flag string) (*memguard.LockedBuffer, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("--%[1]s-prompt needs a terminal, use --%[1]s-stdin or --%[1]s-file instead", flag)
+	}
+	what := strings.ReplaceAll(flag, "-", " ")
+	read := func(prompt string) ([]byte, error) {
+		fmt.Fprint(os.Stderr, prompt)
+		defer fmt.Fprintln(os.Stderr)
+		return term.ReadPassword(fd)
+	}
+	first, err := read(fmt.Sprintf("%s: ", capitalize(what)))
+	defer memguard.WipeBytes(first)
+	if err != nil {
+		return nil, err
+	}
+	second, err := read(fmt.Sprintf("Confirm %s: ", what))
+	defer memguard.WipeBytes(second)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(first, second) {
+		return nil, fmt.Errorf("%ss do not match", what)
+	}
+	return memguard.NewBufferFromBytes(first), nil
+}
+
+func capitalize(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// setPassphrase seals the passphrase read into buf without a trailing line
+// break, destroying buf, and validates it like --passphrase.
+func setPassphrase(ctx *kong.Context, buf *memguard.LockedBuffer) error {
+	enc, err := sealSecret(buf, "passphrase")
+	if err != nil {
+		return err
+	}
+	cfg.Passphrase.enc = enc
+	return validatePassphrase(ctx, cfg.Passphrase.enc)
+}
+
+// setKeyringPassword seals the keyring password read into buf without a
+// trailing line break, destroying buf.
+func setKeyringPassword(buf *memguard.LockedBuffer) error {
+	enc, err := sealSecret(buf, "keyring password")
+	if err != nil {
+		return err
+	}
+	cfg.KeyringPassword.enc = enc
+	return nil
+}
+
+// sealSecret seals the secret called what in buf without a trailing line
+// break, destroying buf.
+func sealSecret(buf *memguard.LockedBuffer, what string) (*memguard.Enclave, error) {
+	defer buf.Destroy()
+	n := len(bytes.TrimRight(buf.Bytes(), "\r\n"))
+	if n == 0 {
+		return nil, fmt.Errorf("%s is empty", what)
+	}
+	trimmed := memguard.NewBuffer(n)
+	trimmed.Copy(buf.Bytes()[:n])
+	return trimmed.Seal(), nil
+}
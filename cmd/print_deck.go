@@ -4,49 +4,58 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/awnumar/memguard"
+	"github.com/alecthomas/kong"
+
 	"github.com/mwmahlberg/solitaire"
 )
 
 type PrintDeck struct {
-	Export bool `kong:"help='print the deck as a sequence suitable for importing'"`
+	Export    bool `kong:"xor='format',help='print the deck as a sequence suitable for importing'"`
+	LetterKey bool `kong:"xor='format',help='print the deck as a key of 55 letters suitable for importing'"`
+	Mnemonic  bool `kong:"xor='format',help='print the deck as 23 words for memorizing, suitable for importing with --deck-mnemonic'"`
+	CheckWeak bool `kong:"help='warn if the keystream of the deck repeats within --budget advances'"`
+	Budget    int  `kong:"default='100000',help='message budget in deck advances for --check-weak'"`
 }
 
-func (p *PrintDeck) Run(pp passphrase) error {
-	s, err := solitaire.New(solitaire.WithPassphraseFromEnclave(cfg.Passphrase.enc))
+func (p *PrintDeck) Run(ctx *kong.Context) error {
+	d, err := deckFromFlags()
 	if err != nil {
-		memguard.SafePanic(err)
+		return err
 	}
-	if p.Export {
-		deck := make([]string, len(s.Deck()))
-		for i, c := range s.Deck() {
-			if c.IsJokerA() {
-				deck[i] = "JA"
-				continue
-			}
-			if c.IsJokerB() {
-				deck[i] = "JB"
-				continue
-			}
-			var color string
-			switch c.Suit() {
-			case solitaire.Clubs:
-				color = "C"
-			case solitaire.Diamonds:
-				color = "D"
-			case solitaire.Hearts:
-				color = "H"
-			case solitaire.Spades:
-				color = "S"
-			}
-			deck[i] = fmt.Sprintf("%s%d", color, c.Rank())
+	if p.CheckWeak {
+		if weak, info := solitaire.IsWeakKey(d, p.Budget); weak {
+			warnf(ctx, "WARN: weak key, the deck repeats after %d advances (cycle length %d, pre-period %d)",
+				info.PrePeriod+info.Length, info.Length, info.PrePeriod)
 		}
-		fmt.Println(strings.Join(deck, ","))
+	}
+	if p.Export {
+		fmt.Println(d.Export())
+		return nil
+	}
+	if p.Mnemonic {
+		fmt.Println(strings.Join(d.Mnemonic(), " "))
+		return nil
+	}
+	if p.LetterKey {
+		fmt.Println(string(solitaire.BlocksOfFive([]byte(d.LetterKey()))))
 		return nil
 	}
 
-	for i, c := range s.Deck() {
-		fmt.Printf("%2d: %s\n", i+1, c.String())
+	printDeck(d)
+	fmt.Printf("\nfingerprint: %s\n", d.Fingerprint())
+	if cfg.AlphabetKey.enc != nil {
+		s, err := solitaire.New(solitaire.WithDeck(&d), solitaire.WithAlphabetKeyFromEnclave(cfg.AlphabetKey.enc))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("alphabet: %s\n", s.Alphabet())
 	}
 	return nil
 }
+
+// printDeck prints the cards of the deck from top to bottom, one per line.
+func printDeck(d solitaire.Deck) {
+	for i, c := range d {
+		fmt.Printf("%2d: %s\n", i+1, c.String())
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mwmahlberg/solitaire"
+)
+
+type splitCmd struct {
+	Shares    int `kong:"short='n',default='5',help='number of shares to create, at most 26'"`
+	Threshold int `kong:"short='k',default='3',help='number of shares needed to restore the deck'"`
+}
+
+func (s *splitCmd) Run() error {
+	d, err := deckFromFlags()
+	if err != nil {
+		return err
+	}
+	shares, err := solitaire.SplitDeck(&d, s.Shares, s.Threshold)
+	if err != nil {
+		return err
+	}
+	for i, share := range shares {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("share %d of %d, %d needed:\n", share.Index, len(shares), share.Threshold)
+		fmt.Println(string(solitaire.BlocksOfFive([]byte(share.String()))))
+	}
+	return nil
+}
+
+type combineCmd struct {
+	Shares []string `kong:"arg,help='shares as printed by split, as one or several arguments'"`
+}
+
+func (c *combineCmd) Run() error {
+	// The letter groups of the shares may be given as separate arguments,
+	// so the shares are cut from all letters.
+	shares, err := solitaire.ParseShares(strings.Join(c.Shares, " "))
+	if err != nil {
+		return err
+	}
+	d, err := solitaire.CombineShares(shares)
+	if err != nil {
+		return err
+	}
+	fmt.Println(d.Export())
+	fmt.Println()
+	fmt.Println(string(solitaire.BlocksOfFive([]byte(d.LetterKey()))))
+	fmt.Println()
+	fmt.Printf("fingerprint: %s\n", d.Fingerprint())
+	return nil
+}
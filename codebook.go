@@ -0,0 +1,288 @@
+package solitaire
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CodebookEntry maps a phrase to its code.
+type CodebookEntry struct {
+	// Phrase is the phrase as written in a cleartext, e.g. "REQUEST RESUPPLY".
+	Phrase string
+	// Code is the letter code replacing the phrase, e.g. "QRS".
+	Code string
+}
+
+// Codebook replaces frequent phrases of a cleartext by short letter codes
+// before encryption and expands the codes after decryption.
+type Codebook struct {
+	entries []CodebookEntry
+	// words are the normalized words of the phrases.
+	words [][]string
+	// encoder matches any of the phrases, longest phrases first.
+	encoder *regexp.Regexp
+}
+
+// NewCodebook returns a codebook for the entries. Phrases must contain
+// letters, codes must consist of at least two letters A to Z; both are
+// normalized like a cleartext. NewCodebook does not check whether the codes
+// are ambiguous, see Check.
+func NewCodebook(entries []CodebookEntry) (*Codebook, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("codebook is empty")
+	}
+	c := &Codebook{entries: make([]CodebookEntry, len(entries)), words: make([][]string, len(entries))}
+	for i, e := range entries {
+		code := normalizeCleartext([]byte(e.Code))
+		if len(code) < 2 || len(code) != len(strings.TrimSpace(e.Code)) {
+			return nil, fmt.Errorf("invalid code %q for %q: must be at least two letters A to Z", e.Code, e.Phrase)
+		}
+		c.words[i] = phraseWords(e.Phrase)
+		if len(c.words[i]) == 0 {
+			return nil, fmt.Errorf("invalid phrase %q for code %q: must contain letters", e.Phrase, e.Code)
+		}
+		c.entries[i] = CodebookEntry{Phrase: strings.Join(c.words[i], " "), Code: string(code)}
+	}
+
+	// The phrases are matched as whole words, case-insensitive and with any
+	// non-letters between their words. The case is folded with ASCII classes
+	// like [Aa], as (?i) would also match e.g. 'ſ' for 'S', which codeOf
+	// cannot map back to the phrase. Go's regexp prefers the first matching
+	// alternative, so longer phrases are tried first.
+	byLength := make([]int, len(c.entries))
+	for i := range byLength {
+		byLength[i] = i
+	}
+	sort.SliceStable(byLength, func(i, j int) bool {
+		return len(c.entries[byLength[i]].Phrase) > len(c.entries[byLength[j]].Phrase)
+	})
+	alternatives := make([]string, len(byLength))
+	for k, i := range byLength {
+		quoted := make([]string, len(c.words[i]))
+		for j, w := range c.words[i] {
+			quoted[j] = foldASCII(w)
+		}
+		alternatives[k] = strings.Join(quoted, `[^\pL]+`)
+	}
+	c.encoder = regexp.MustCompile(`(^|[^\pL])(` + strings.Join(alternatives, "|") + `)([^\pL]|$)`)
+	return c, nil
+}
+
+// foldASCII returns a pattern matching the word of letters A to Z in any case.
+func foldASCII(word string) string {
+	var b strings.Builder
+	for _, c := range []byte(word) {
+		b.WriteString("[" + string(c) + string(c-'A'+'a') + "]")
+	}
+	return b.String()
+}
+
+// LoadCodebookCSV reads a codebook with one "phrase,code" record per line.
+// Lines starting with '#' are ignored, as is a header "phrase,code".
+func LoadCodebookCSV(r io.Reader) (*Codebook, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 && strings.EqualFold(records[0][0], "phrase") && strings.EqualFold(records[0][1], "code") {
+		records = records[1:]
+	}
+	entries := make([]CodebookEntry, len(records))
+	for i, rec := range records {
+		entries[i] = CodebookEntry{Phrase: rec[0], Code: rec[1]}
+	}
+	return NewCodebook(entries)
+}
+
+// LoadCodebookYAML reads a codebook from a YAML mapping of phrases to codes,
+// e.g. "REQUEST RESUPPLY: QRS".
+func LoadCodebookYAML(r io.Reader) (*Codebook, error) {
+	var m map[string]string
+	if err := yaml.NewDecoder(r).Decode(&m); err != nil && err != io.EOF {
+		return nil, err
+	}
+	entries := make([]CodebookEntry, 0, len(m))
+	for phrase, code := range m {
+		entries = append(entries, CodebookEntry{Phrase: phrase, Code: code})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Phrase < entries[j].Phrase })
+	return NewCodebook(entries)
+}
+
+// Entries returns the normalized entries of the codebook.
+func (c *Codebook) Entries() []CodebookEntry {
+	return append([]CodebookEntry{}, c.entries...)
+}
+
+// Encode replaces the phrases in the cleartext by their codes.
+func (c *Codebook) Encode(cleartext []byte) []byte {
+	var out []byte
+	rest := cleartext
+	for {
+		m := c.encoder.FindSubmatchIndex(rest)
+		if m == nil {
+			return append(out, rest...)
+		}
+		// m[4:6] is the phrase, without the non-letters around it.
+		out = append(out, rest[:m[4]]...)
+		out = append(out, c.codeOf(rest[m[4]:m[5]])...)
+		rest = rest[m[5]:]
+	}
+}
+
+// codeOf returns the code of the matched phrase.
+func (c *Codebook) codeOf(match []byte) string {
+	phrase := strings.Join(phraseWords(string(match)), " ")
+	for _, e := range c.entries {
+		if e.Phrase == phrase {
+			return e.Code
+		}
+	}
+	panic("matched phrase not in codebook")
+}
+
+// phraseWords returns the words of a phrase, normalized like a cleartext.
+func phraseWords(phrase string) []string {
+	var words []string
+	for _, w := range nonLetters.Split(phrase, -1) {
+		if w := normalizeCleartext([]byte(w)); len(w) > 0 {
+			words = append(words, string(w))
+		}
+	}
+	return words
+}
+
+// Expand replaces the codes in a decrypted plaintext by their phrases,
+// separated from the other letters by spaces. Non-letters, like the spaces
+// between blocks of five, are dropped. If codes overlap, the longest code
+// starting at the leftmost position is expanded.
+func (c *Codebook) Expand(plaintext []byte) []byte {
+	letters := normalizeCleartext(plaintext)
+	var out []byte
+	sep := func() {
+		if len(out) > 0 && out[len(out)-1] != ' ' {
+			out = append(out, ' ')
+		}
+	}
+	for i := 0; i < len(letters); {
+		best := -1
+		for j, e := range c.entries {
+			if bytes.HasPrefix(letters[i:], []byte(e.Code)) && (best < 0 || len(e.Code) > len(c.entries[best].Code)) {
+				best = j
+			}
+		}
+		if best < 0 {
+			out = append(out, letters[i])
+			i++
+			continue
+		}
+		sep()
+		out = append(out, c.entries[best].Phrase...)
+		out = append(out, ' ')
+		i += len(c.entries[best].Code)
+	}
+	return bytes.TrimRight(out, " ")
+}
+
+// CodebookIssueKind is the kind of a problem found by Check.
+type CodebookIssueKind int
+
+const (
+	// DuplicateCode means that two phrases have the same code.
+	DuplicateCode CodebookIssueKind = iota
+	// DuplicatePhrase means that a phrase has more than one code.
+	DuplicatePhrase
+	// OverlappingCode means that a code is part of a longer code, so the
+	// letters of the longer code may be expanded wrongly.
+	OverlappingCode
+	// CodeInWord means that a code occurs in a word of a registered
+	// dictionary, so the word would be expanded when decrypting.
+	CodeInWord
+)
+
+func (k CodebookIssueKind) String() string {
+	switch k {
+	case DuplicateCode:
+		return "duplicate code"
+	case DuplicatePhrase:
+		return "duplicate phrase"
+	case OverlappingCode:
+		return "overlapping code"
+	case CodeInWord:
+		return "code in word"
+	default:
+		panic("invalid codebook issue kind")
+	}
+}
+
+// CodebookIssue is a problem of a codebook found by Check.
+type CodebookIssue struct {
+	Kind CodebookIssueKind
+	// Entry is the entry the issue was found for.
+	Entry CodebookEntry
+	// Other is the conflicting phrase, code or word, depending on the kind.
+	Other string
+}
+
+func (i CodebookIssue) String() string {
+	switch i.Kind {
+	case DuplicateCode:
+		return fmt.Sprintf("code %s is used for %q and %q", i.Entry.Code, i.Entry.Phrase, i.Other)
+	case DuplicatePhrase:
+		return fmt.Sprintf("phrase %q has the codes %s and %s", i.Entry.Phrase, i.Entry.Code, i.Other)
+	case OverlappingCode:
+		return fmt.Sprintf("code %s of %q is part of code %s", i.Entry.Code, i.Entry.Phrase, i.Other)
+	case CodeInWord:
+		return fmt.Sprintf("code %s of %q occurs in the word %s", i.Entry.Code, i.Entry.Phrase, i.Other)
+	default:
+		return i.Kind.String()
+	}
+}
+
+// Check finds codes that cannot be expanded unambiguously: codes used for
+// several phrases, phrases with several codes, codes contained in other codes
+// and codes occurring in words of the registered dictionaries. For the latter,
+// the most frequent such word is reported.
+func (c *Codebook) Check() []CodebookIssue {
+	issues := make([]CodebookIssue, 0)
+	for i, e := range c.entries {
+		for _, o := range c.entries[:i] {
+			switch {
+			case e.Code == o.Code:
+				issues = append(issues, CodebookIssue{Kind: DuplicateCode, Entry: e, Other: o.Phrase})
+			case e.Phrase == o.Phrase:
+				issues = append(issues, CodebookIssue{Kind: DuplicatePhrase, Entry: e, Other: o.Code})
+			}
+		}
+		for _, o := range c.entries {
+			if len(o.Code) > len(e.Code) && strings.Contains(o.Code, e.Code) {
+				issues = append(issues, CodebookIssue{Kind: OverlappingCode, Entry: e, Other: o.Code})
+			}
+		}
+
+		word, best := "", 0.0
+		for _, name := range Dictionaries() {
+			for w, p := range LookupDictionary(name).logProb {
+				if strings.Contains(w, e.Code) && (word == "" || p > best || (p == best && w < word)) {
+					word, best = w, p
+				}
+			}
+		}
+		if word != "" {
+			issues = append(issues, CodebookIssue{Kind: CodeInWord, Entry: e, Other: word})
+		}
+	}
+	return issues
+}
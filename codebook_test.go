@@ -0,0 +1,120 @@
+package solitaire
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CodebookSuite struct {
+	suite.Suite
+	codebook *Codebook
+}
+
+const testCodebook = `phrase,code
+# supplies
+REQUEST RESUPPLY,QRS
+request resupply of ammunition,QRA
+Hamburg-Altona,QHA
+`
+
+func (s *CodebookSuite) SetupTest() {
+	var err error
+	s.codebook, err = LoadCodebookCSV(strings.NewReader(testCodebook))
+	s.Require().NoError(err)
+}
+
+func (s *CodebookSuite) TestEntries() {
+	s.Equal([]CodebookEntry{
+		{Phrase: "REQUEST RESUPPLY", Code: "QRS"},
+		{Phrase: "REQUEST RESUPPLY OF AMMUNITION", Code: "QRA"},
+		{Phrase: "HAMBURG ALTONA", Code: "QHA"},
+	}, s.codebook.Entries())
+}
+
+func (s *CodebookSuite) TestEncode() {
+	testCases := []struct {
+		desc      string
+		cleartext string
+		encoded   string
+	}{
+		{desc: "phrase", cleartext: "Request resupply now", encoded: "QRS now"},
+		{desc: "longest phrase", cleartext: "Request  resupply of\nammunition!", encoded: "QRA!"},
+		{desc: "non-letters between words", cleartext: "Meet at hamburg altona", encoded: "Meet at QHA"},
+		{desc: "whole words only", cleartext: "REQUEST RESUPPLYING", encoded: "REQUEST RESUPPLYING"},
+		{desc: "several phrases", cleartext: "HAMBURG-ALTONA REQUEST RESUPPLY", encoded: "QHA QRS"},
+		{desc: "no phrase", cleartext: "SOLITAIRE", encoded: "SOLITAIRE"},
+		{desc: "no Unicode case folding", cleartext: "request reſupply now", encoded: "request reſupply now"},
+	}
+	for _, tC := range testCases {
+		s.Run(tC.desc, func() {
+			s.Equal(tC.encoded, string(s.codebook.Encode([]byte(tC.cleartext))))
+		})
+	}
+}
+
+func (s *CodebookSuite) TestExpand() {
+	s.Equal("HAMBURG ALTONA REQUEST RESUPPLY NOW", string(s.codebook.Expand([]byte("QHAQR SNOW"))))
+	s.Equal("SEND REQUEST RESUPPLY OF AMMUNITION XX", string(s.codebook.Expand([]byte("SENDQ RAXX"))))
+	s.Equal("SOLITAIRE", string(s.codebook.Expand([]byte("SOLIT AIRE"))))
+}
+
+func (s *CodebookSuite) TestRoundTrip() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")), WithCodebook(s.codebook))
+	s.Require().NoError(err)
+	ct, err := sol.Encrypt([]byte("Request resupply of ammunition at Hamburg-Altona"))
+	s.Require().NoError(err)
+	s.Len(normalizeCleartext(ct), 10, "The phrases are replaced by codes")
+
+	sol, err = New(WithPassphrase([]byte("CRYPTONOMICON")), WithCodebook(s.codebook))
+	s.Require().NoError(err)
+	pt, err := sol.Decrypt(ct)
+	s.Require().NoError(err)
+	s.Equal("REQUEST RESUPPLY OF AMMUNITION AT HAMBURG ALTONA XX", string(pt))
+
+	_, err = New(WithPassphrase([]byte("CRYPTONOMICON")), WithCodebook(s.codebook), WithPreservedFormat())
+	s.Error(err)
+	_, err = sol.Repair(ct, 3)
+	s.Error(err, "A repaired plaintext is not expanded")
+}
+
+func (s *CodebookSuite) TestLoadCodebookYAML() {
+	c, err := LoadCodebookYAML(strings.NewReader("REQUEST RESUPPLY: QRS\nBravo Six: qbs\n"))
+	s.Require().NoError(err)
+	s.Equal([]CodebookEntry{{Phrase: "BRAVO SIX", Code: "QBS"}, {Phrase: "REQUEST RESUPPLY", Code: "QRS"}}, c.Entries())
+}
+
+func (s *CodebookSuite) TestInvalid() {
+	for _, input := range []string{"", "phrase,code\n", "REQUEST,Q\n", "REQUEST,Q1\n", "123,QRS\n", "REQUEST\n"} {
+		_, err := LoadCodebookCSV(strings.NewReader(input))
+		s.Error(err, input)
+	}
+	_, err := LoadCodebookYAML(strings.NewReader("- QRS\n"))
+	s.Error(err)
+}
+
+func (s *CodebookSuite) TestCheck() {
+	c, err := NewCodebook([]CodebookEntry{
+		{Phrase: "REQUEST RESUPPLY", Code: "QRS"},
+		{Phrase: "RETURN TO BASE", Code: "QRS"},
+		{Phrase: "REQUEST RESUPPLY", Code: "QRR"},
+		{Phrase: "RADIO SILENCE", Code: "QRSX"},
+		{Phrase: "BRAVO SIX", Code: "THE"},
+	})
+	s.Require().NoError(err)
+	issues := c.Check()
+	kinds := make([]CodebookIssueKind, len(issues))
+	for i, issue := range issues {
+		kinds[i] = issue.Kind
+		s.NotEmpty(issue.String())
+	}
+	s.Equal([]CodebookIssueKind{OverlappingCode, DuplicateCode, OverlappingCode, DuplicatePhrase, CodeInWord}, kinds)
+	s.Equal(CodebookIssue{Kind: CodeInWord, Entry: CodebookEntry{Phrase: "BRAVO SIX", Code: "THE"}, Other: "THE"}, issues[4])
+
+	s.Empty(s.codebook.Check())
+}
+
+func TestCodebook(t *testing.T) {
+	suite.Run(t, new(CodebookSuite))
+}
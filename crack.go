@@ -0,0 +1,397 @@
+package solitaire
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"iter"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WordlistCandidates returns the words as passphrase candidates, in order.
+// Sorting the words improves the reuse of common prefixes by Crack.
+func WordlistCandidates(words [][]byte) iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		for _, w := range words {
+			if !yield(w) {
+				return
+			}
+		}
+	}
+}
+
+// CombinationCandidates returns all concatenations of one up to maxWords of
+// the words, with repetition. The candidates are generated depth first, so
+// consecutive candidates share the longest possible prefix.
+func CombinationCandidates(words [][]byte, maxWords int) iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		var combine func(prefix []byte, depth int) bool
+		combine = func(prefix []byte, depth int) bool {
+			for _, w := range words {
+				candidate := append(prefix, w...)
+				if !yield(candidate) {
+					return false
+				}
+				if depth < maxWords && !combine(candidate, depth+1) {
+					return false
+				}
+			}
+			return true
+		}
+		combine(make([]byte, 0, 64), 1)
+	}
+}
+
+// MaskCandidates returns all passphrases matching the mask.
+// In the mask, ?u stands for any letter from A to Z, ?l for any letter from a to z
+// and ?? for a literal question mark. All other characters stand for themselves.
+// The last position of the mask varies fastest.
+func MaskCandidates(mask string) (iter.Seq[[]byte], error) {
+	var positions [][]byte
+	for i := 0; i < len(mask); i++ {
+		if mask[i] != '?' {
+			positions = append(positions, []byte{mask[i]})
+			continue
+		}
+		if i+1 == len(mask) {
+			return nil, fmt.Errorf("mask ends with an incomplete placeholder")
+		}
+		i++
+		switch mask[i] {
+		case 'u':
+			positions = append(positions, alphabet[:])
+		case 'l':
+			positions = append(positions, bytes.ToLower(alphabet[:]))
+		case '?':
+			positions = append(positions, []byte{'?'})
+		default:
+			return nil, fmt.Errorf("unknown placeholder ?%c in mask", mask[i])
+		}
+	}
+
+	return func(yield func([]byte) bool) {
+		if len(positions) == 0 {
+			return
+		}
+		counters := make([]int, len(positions))
+		candidate := make([]byte, len(positions))
+		for i, p := range positions {
+			candidate[i] = p[0]
+		}
+		for {
+			if !yield(candidate) {
+				return
+			}
+			// Increment the counters like an odometer.
+			i := len(counters) - 1
+			for ; i >= 0; i-- {
+				counters[i]++
+				if counters[i] < len(positions[i]) {
+					candidate[i] = positions[i][counters[i]]
+					break
+				}
+				counters[i] = 0
+				candidate[i] = positions[i][0]
+			}
+			if i < 0 {
+				return
+			}
+		}
+	}, nil
+}
+
+// CrackConfig configures a known-plaintext attack with Crack.
+type CrackConfig struct {
+	// Ciphertext is the intercepted ciphertext. Non-letters are ignored.
+	Ciphertext []byte
+	// Crib is the known plaintext. It is normalized like a cleartext.
+	Crib []byte
+	// Offset is the position of the crib in the plaintext letters,
+	// or a negative number if the position is unknown.
+	Offset int
+	// Workers is the number of goroutines testing candidates.
+	// It defaults to the number of CPUs.
+	Workers int
+	// MaxMatches stops the attack after the given number of matches.
+	// Zero means that all candidates are tried.
+	MaxMatches int
+	// Progress is called periodically from a separate goroutine with the
+	// progress of the attack, and once more when the attack ends.
+	// Calls to Progress never overlap.
+	Progress func(CrackProgress)
+	// ProgressInterval is the interval between calls to Progress.
+	// It defaults to one second.
+	ProgressInterval time.Duration
+}
+
+// CrackProgress reports the progress of Crack.
+type CrackProgress struct {
+	// Tried is the number of candidates tested so far.
+	Tried int64
+	// Matches is the number of matches found so far.
+	Matches int
+	// Elapsed is the time since the attack was started.
+	Elapsed time.Duration
+}
+
+// Rate returns the number of candidates tested per second, or zero if too
+// little time has elapsed to tell.
+func (p CrackProgress) Rate() float64 {
+	if p.Elapsed < time.Millisecond {
+		return 0
+	}
+	return float64(p.Tried) / p.Elapsed.Seconds()
+}
+
+// CrackMatch is a passphrase whose keystream matches the crib.
+type CrackMatch struct {
+	Passphrase []byte
+	// Offset is the position of the crib in the plaintext letters.
+	Offset int
+}
+
+// crackBatchSize is the number of consecutive candidates handed to a worker
+// at once. Keeping consecutive candidates together lets the worker reuse the
+// deck keyed with their common prefix.
+const crackBatchSize = 512
+
+// Crack tries the passphrase candidates against the ciphertext, using the
+// crib as known plaintext, and returns the candidates whose keystream
+// turns the crib into the ciphertext.
+// Candidates are keyed exactly like WithPassphrase does. The candidates may
+// reuse the memory of the yielded slices between iterations.
+// If ctx is cancelled, Crack stops iterating the candidates and returns the
+// matches found so far together with the error of the context.
+func Crack(ctx context.Context, cfg CrackConfig, candidates iter.Seq[[]byte]) ([]CrackMatch, error) {
+	checker, err := newCribChecker(cfg.Ciphertext, cfg.Crib, cfg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	interval := cfg.ProgressInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		tried   atomic.Int64
+		found   atomic.Int64
+		start   = time.Now()
+		batches = make(chan [][]byte, workers)
+		results = make(chan CrackMatch)
+		wg      sync.WaitGroup
+		// producerDone is closed once the candidates are no longer iterated,
+		// so that Crack does not return while the iterator is still running.
+		producerDone = make(chan struct{})
+	)
+
+	go func() {
+		defer close(producerDone)
+		defer close(batches)
+		batch := make([][]byte, 0, crackBatchSize)
+		for c := range candidates {
+			if ctx.Err() != nil {
+				return
+			}
+			batch = append(batch, bytes.Clone(c))
+			if len(batch) < crackBatchSize {
+				continue
+			}
+			select {
+			case batches <- batch:
+			case <-ctx.Done():
+				return
+			}
+			batch = make([][]byte, 0, crackBatchSize)
+		}
+		if len(batch) > 0 {
+			select {
+			case batches <- batch:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			keyer := &prefixKeyer{}
+			for batch := range batches {
+				for _, c := range batch {
+					if ctx.Err() != nil {
+						return
+					}
+					offset, ok := checker.check(*keyer.key(c))
+					tried.Add(1)
+					if !ok {
+						continue
+					}
+					select {
+					case results <- CrackMatch{Passphrase: c, Offset: offset}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	report := func() {
+		if cfg.Progress != nil {
+			cfg.Progress(CrackProgress{Tried: tried.Load(), Matches: int(found.Load()), Elapsed: time.Since(start)})
+		}
+	}
+	done := make(chan struct{})
+	reporterDone := make(chan struct{})
+	go func() {
+		defer close(reporterDone)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				report()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	matches := make([]CrackMatch, 0)
+	for m := range results {
+		matches = append(matches, m)
+		found.Add(1)
+		if cfg.MaxMatches > 0 && len(matches) >= cfg.MaxMatches {
+			cancel()
+		}
+	}
+	// The workers stop once the candidates are exhausted or ctx is done, in
+	// both cases the producer has returned or is about to.
+	<-producerDone
+	close(done)
+	<-reporterDone
+	report()
+
+	// Only report the error of the caller's context, not the cancellation
+	// after reaching MaxMatches.
+	if cfg.MaxMatches > 0 && len(matches) >= cfg.MaxMatches {
+		return matches[:cfg.MaxMatches], nil
+	}
+	return matches, context.Cause(ctx)
+}
+
+// prefixKeyer keys decks with passphrases, reusing the deck keyed with the
+// prefix the passphrase shares with the previous one.
+type prefixKeyer struct {
+	prev []byte
+	// states[i] is the deck keyed with prev[:i].
+	states []Deck
+}
+
+func (k *prefixKeyer) key(passphrase []byte) *Deck {
+	if len(k.states) == 0 {
+		var d Deck
+		copy(d[:], initialDeck)
+		k.states = append(k.states, d)
+	}
+	n := 0
+	for n < len(k.prev) && n < len(passphrase) && k.prev[n] == passphrase[n] {
+		n++
+	}
+	k.states = k.states[:n+1]
+	k.prev = append(k.prev[:n], passphrase[n:]...)
+	for _, c := range passphrase[n:] {
+		d := k.states[len(k.states)-1]
+		d.key([]byte{c})
+		k.states = append(k.states, d)
+	}
+	return &k.states[len(passphrase)]
+}
+
+// cribChecker checks whether the keystream of a deck turns the crib into the ciphertext.
+type cribChecker struct {
+	ciphertext []byte
+	crib       []byte
+	offset     int
+}
+
+func newCribChecker(ciphertext, crib []byte, offset int) (*cribChecker, error) {
+	c := &cribChecker{
+		ciphertext: normalizeCleartext(ciphertext),
+		crib:       normalizeCleartext(crib),
+		offset:     offset,
+	}
+	if len(c.crib) == 0 {
+		return nil, fmt.Errorf("crib must contain letters")
+	}
+	if c.offset >= 0 && c.offset+len(c.crib) > len(c.ciphertext) {
+		return nil, fmt.Errorf("crib at offset %d exceeds the ciphertext of %d letters", c.offset, len(c.ciphertext))
+	}
+	if len(c.crib) > len(c.ciphertext) {
+		return nil, fmt.Errorf("crib is longer than the ciphertext")
+	}
+	return c, nil
+}
+
+// cribMatches reports whether the keystream value v encrypts the plaintext letter
+// p into the ciphertext letter c.
+func cribMatches(v int, p, c byte) bool {
+	return (int(p-'A')+v)%26 == int(c-'A')
+}
+
+// check returns the position of the crib in the plaintext if the keystream of
+// d matches. The deck is modified.
+func (c *cribChecker) check(d Deck) (int, bool) {
+	next := func() int {
+		for {
+			d.Advance()
+			if v := d.Output(); v < 53 {
+				return v
+			}
+		}
+	}
+
+	if c.offset >= 0 {
+		for range c.offset {
+			next()
+		}
+		for i, p := range c.crib {
+			if !cribMatches(next(), p, c.ciphertext[c.offset+i]) {
+				return 0, false
+			}
+		}
+		return c.offset, true
+	}
+
+	keys := make([]int, len(c.ciphertext))
+	for i := range keys {
+		keys[i] = next()
+	}
+	for offset := 0; offset+len(c.crib) <= len(keys); offset++ {
+		ok := true
+		for i, p := range c.crib {
+			if !cribMatches(keys[offset+i], p, c.ciphertext[offset+i]) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return offset, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,175 @@
+package solitaire
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CrackSuite struct {
+	suite.Suite
+}
+
+func (s *CrackSuite) encrypt(passphrase, plaintext string) []byte {
+	sol, err := New(WithPassphrase([]byte(passphrase)))
+	s.Require().NoError(err)
+	ct, err := sol.Encrypt([]byte(plaintext))
+	s.Require().NoError(err)
+	return ct
+}
+
+func (s *CrackSuite) collect(candidates func(func([]byte) bool)) []string {
+	result := make([]string, 0)
+	for c := range candidates {
+		result = append(result, string(c))
+	}
+	return result
+}
+
+func (s *CrackSuite) TestCombinationCandidates() {
+	words := [][]byte{[]byte("A"), []byte("BC")}
+	s.Equal([]string{"A", "AA", "ABC", "BC", "BCA", "BCBC"}, s.collect(CombinationCandidates(words, 2)))
+}
+
+func (s *CrackSuite) TestMaskCandidates() {
+	candidates, err := MaskCandidates("X?u??")
+	s.Require().NoError(err)
+	result := s.collect(candidates)
+	s.Len(result, 26)
+	s.Equal("XA?", result[0])
+	s.Equal("XZ?", result[25])
+
+	_, err = MaskCandidates("X?")
+	s.Error(err)
+	_, err = MaskCandidates("?d")
+	s.Error(err)
+}
+
+func (s *CrackSuite) TestPrefixKeyer() {
+	keyer := &prefixKeyer{}
+	for _, p := range []string{"FOO", "FOOBAR", "FOB", "", "CRYPTONOMICON", "CRYPT"} {
+		sol, err := New(WithPassphrase([]byte(p)))
+		s.Require().NoError(err)
+		s.Equal(*sol.deck, *keyer.key([]byte(p)), p)
+	}
+}
+
+func (s *CrackSuite) TestCrackKnownOffset() {
+	ct := s.encrypt("SECRET", "ATTACK AT DAWN")
+	candidates, err := MaskCandidates("SEC?u?uT")
+	s.Require().NoError(err)
+	matches, err := Crack(context.Background(), CrackConfig{
+		Ciphertext: ct,
+		Crib:       []byte("attack"),
+		Offset:     0,
+		Workers:    2,
+	}, candidates)
+	s.Require().NoError(err)
+	s.Require().Len(matches, 1)
+	s.Equal("SECRET", string(matches[0].Passphrase))
+	s.Equal(0, matches[0].Offset)
+}
+
+func (s *CrackSuite) TestCrackUnknownOffset() {
+	ct := s.encrypt("BLUEMOON", "MEET ME AT THE BRIDGE AT NOON")
+	words := [][]byte{[]byte("BLUE"), []byte("GREEN"), []byte("MOON"), []byte("SUN")}
+	matches, err := Crack(context.Background(), CrackConfig{
+		Ciphertext: ct,
+		Crib:       []byte("BRIDGE"),
+		Offset:     -1,
+		MaxMatches: 1,
+	}, CombinationCandidates(words, 3))
+	s.Require().NoError(err)
+	s.Require().Len(matches, 1)
+	s.Equal("BLUEMOON", string(matches[0].Passphrase))
+	s.Equal(strings.Index("MEETMEATTHEBRIDGEATNOON", "BRIDGE"), matches[0].Offset)
+}
+
+func (s *CrackSuite) TestCrackCancelled() {
+	ct := s.encrypt("SECRET", "ATTACK AT DAWN")
+	candidates, err := MaskCandidates("?u?u?u?u?u?u?u")
+	s.Require().NoError(err)
+	ctx, cancel := context.WithCancel(context.Background())
+	var progress []CrackProgress
+	_, err = Crack(ctx, CrackConfig{
+		Ciphertext: ct,
+		Crib:       []byte("ATTACK"),
+		Offset:     0,
+		Progress: func(p CrackProgress) {
+			progress = append(progress, p)
+			cancel()
+		},
+		ProgressInterval: 10_000_000,
+	}, candidates)
+	s.ErrorIs(err, context.Canceled)
+	s.NotEmpty(progress)
+}
+
+func (s *CrackSuite) TestCrackStopsCandidates() {
+	ct := s.encrypt("SECRET", "ATTACK AT DAWN")
+	ctx, cancel := context.WithCancel(context.Background())
+	var iterating atomic.Bool
+	candidates := func(yield func([]byte) bool) {
+		iterating.Store(true)
+		defer iterating.Store(false)
+		for i := 0; ; i++ {
+			if i == 1000 {
+				cancel()
+			}
+			if !yield([]byte("NOTSECRET")) {
+				return
+			}
+		}
+	}
+	_, err := Crack(ctx, CrackConfig{
+		Ciphertext: ct,
+		Crib:       []byte("ATTACK"),
+		Offset:     0,
+		Workers:    1,
+	}, candidates)
+	s.ErrorIs(err, context.Canceled)
+	s.False(iterating.Load(), "The candidates are no longer iterated after Crack returns")
+}
+
+func (s *CrackSuite) TestCrackCountsCandidates() {
+	ct := s.encrypt("SECRET", "ATTACK AT DAWN")
+	words := make([][]byte, crackBatchSize)
+	for i := range words {
+		words[i] = []byte("NOTSECRET")
+	}
+	words[100] = []byte("SECRET")
+	var last CrackProgress
+	matches, err := Crack(context.Background(), CrackConfig{
+		Ciphertext: ct,
+		Crib:       []byte("ATTACK"),
+		Offset:     0,
+		Workers:    1,
+		MaxMatches: 1,
+		Progress:   func(p CrackProgress) { last = p },
+	}, WordlistCandidates(words))
+	s.Require().NoError(err)
+	s.Len(matches, 1)
+	// The batch is left unfinished after the match, but the candidates
+	// tested so far are counted.
+	s.GreaterOrEqual(last.Tried, int64(101))
+}
+
+func (s *CrackSuite) TestCrackProgressRate() {
+	s.Zero(CrackProgress{Tried: 100}.Rate())
+	s.InDelta(50.0, CrackProgress{Tried: 100, Elapsed: 2 * time.Second}.Rate(), 1e-9)
+}
+
+func (s *CrackSuite) TestCrackInvalidCrib() {
+	_, err := Crack(context.Background(), CrackConfig{Ciphertext: []byte("ABCDE"), Crib: []byte("123")}, nil)
+	s.Error(err)
+	_, err = Crack(context.Background(), CrackConfig{Ciphertext: []byte("ABCDE"), Crib: []byte("ABC"), Offset: 3}, nil)
+	s.Error(err)
+}
+
+func TestCrack(t *testing.T) {
+	suite.Run(t, new(CrackSuite))
+}
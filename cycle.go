@@ -0,0 +1,86 @@
+package solitaire
+
+// CycleInfo describes the cycle structure of the sequence of deck states
+// produced by repeatedly advancing a deck.
+type CycleInfo struct {
+	// Found reports whether a cycle was detected within the step limit.
+	// If it is false, Length and PrePeriod are zero.
+	Found bool
+	// Length is the number of advances after which the deck state repeats.
+	Length int
+	// PrePeriod is the number of advances before the deck enters the cycle.
+	PrePeriod int
+	// Steps is the total number of advances performed during the analysis.
+	Steps int
+}
+
+// FindCycle determines the cycle length and the pre-period of the sequence of
+// deck states starting at d, using Brent's cycle detection algorithm.
+// The search gives up after limit advances; in that case the returned
+// CycleInfo has Found set to false.
+// The deck d is not modified.
+func FindCycle(d Deck, limit int) CycleInfo {
+	return brent(d, func(d Deck) Deck {
+		d.Advance()
+		return d
+	}, limit)
+}
+
+// brent implements Brent's cycle detection for the sequence
+// x0, next(x0), next(next(x0)), ...
+func brent[T comparable](x0 T, next func(T) T, limit int) CycleInfo {
+	info := CycleInfo{}
+
+	// Find the cycle length by searching for the smallest power of two
+	// that is at least as large as the cycle length.
+	power, length := 1, 1
+	tortoise := x0
+	hare := next(x0)
+	info.Steps++
+	for tortoise != hare {
+		if info.Steps >= limit {
+			return info
+		}
+		if power == length {
+			tortoise = hare
+			power *= 2
+			length = 0
+		}
+		hare = next(hare)
+		info.Steps++
+		length++
+	}
+
+	// Find the pre-period by moving two states, length steps apart,
+	// in lockstep until they match.
+	tortoise = x0
+	hare = x0
+	for i := 0; i < length; i++ {
+		hare = next(hare)
+		info.Steps++
+	}
+	prePeriod := 0
+	for tortoise != hare {
+		tortoise = next(tortoise)
+		hare = next(hare)
+		info.Steps += 2
+		prePeriod++
+	}
+
+	info.Found = true
+	info.Length = length
+	info.PrePeriod = prePeriod
+	return info
+}
+
+// IsWeakKey reports whether the keystream produced by d repeats within budget
+// advances of the deck, which is slightly more than the number of letters that
+// can be encrypted with budget keystream values.
+// The returned CycleInfo contains the details of the cycle search.
+func IsWeakKey(d Deck, budget int) (bool, CycleInfo) {
+	// Brent's algorithm needs at most prePeriod + 2*length advances to find
+	// the cycle length, so searching up to 3*budget catches all cycles
+	// that close within budget advances.
+	info := FindCycle(d, 3*budget)
+	return info.Found && info.PrePeriod+info.Length <= budget, info
+}
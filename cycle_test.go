@@ -0,0 +1,78 @@
+package solitaire
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CycleSuite struct {
+	suite.Suite
+}
+
+func (s *CycleSuite) TestBrent() {
+	testCases := []struct {
+		desc      string
+		next      func(int) int
+		length    int
+		prePeriod int
+	}{
+		{
+			desc:      "pure cycle",
+			next:      func(x int) int { return (x + 1) % 7 },
+			length:    7,
+			prePeriod: 0,
+		},
+		{
+			desc: "cycle with tail",
+			next: func(x int) int {
+				if x < 10 {
+					return x + 1
+				}
+				return 10 + (x-10+1)%5
+			},
+			length:    5,
+			prePeriod: 10,
+		},
+		{
+			desc:      "fixed point",
+			next:      func(x int) int { return 0 },
+			length:    1,
+			prePeriod: 0,
+		},
+	}
+	for _, tC := range testCases {
+		s.Run(tC.desc, func() {
+			info := brent(0, tC.next, 1000)
+			s.True(info.Found)
+			s.Equal(tC.length, info.Length)
+			s.Equal(tC.prePeriod, info.PrePeriod)
+		})
+	}
+}
+
+func (s *CycleSuite) TestBrentLimit() {
+	info := brent(0, func(x int) int { return x + 1 }, 100)
+	s.False(info.Found)
+	s.Equal(100, info.Steps)
+}
+
+func (s *CycleSuite) TestFindCycleDoesNotModifyDeck() {
+	d := Deck{}
+	copy(d[:], initialDeck)
+	info := FindCycle(d, 1000)
+	s.False(info.Found, "Expected no short cycle for the initial deck")
+	s.Equal(initialDeck, d[:])
+}
+
+func (s *CycleSuite) TestIsWeakKey() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	weak, info := IsWeakKey(*sol.deck, 1000)
+	s.False(weak)
+	s.False(info.Found)
+}
+
+func TestCycle(t *testing.T) {
+	suite.Run(t, new(CycleSuite))
+}
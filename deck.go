@@ -1,5 +1,11 @@
 package solitaire
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 type Deck [54]Card
 
 func (d *Deck) Advance() {
@@ -12,6 +18,15 @@ func (d *Deck) Advance() {
 	d.CountCut()
 }
 
+// key keys the deck with the passphrase: for each letter, the deck is advanced
+// and then count cut at the position of the letter in the alphabet.
+func (d *Deck) key(passphrase []byte) {
+	for _, c := range passphrase {
+		d.Advance()
+		d.countCut(alphabet.Index(c) + 1)
+	}
+}
+
 func (d *Deck) Move(pos, by int) {
 	// Move the card at the specified position by the specified number of positions
 	// in the deck.
@@ -114,3 +129,116 @@ func (d *Deck) moveCard(srcIndex int, dstIndex int) {
 	d.RemoveCard(srcIndex)
 	d.InsertCard(value, dstIndex)
 }
+
+// Output returns the value of the output card for the current state of the deck.
+// The output card is found by counting down from the top as many cards as the
+// value of the top card. Both jokers count as 53, so a return value of 53 means
+// that the current state does not produce a keystream value.
+func (d *Deck) Output() int {
+	return d[d[0].Value()].Value()
+}
+
+// Validate checks that the deck contains each of the 52 suit cards and both
+// jokers exactly once.
+func (d *Deck) Validate() error {
+	seen := make(map[Card]bool, len(d))
+	for i, c := range d {
+		if !isValidCard(c) {
+			return fmt.Errorf("invalid card at position %d", i+1)
+		}
+		if seen[c] {
+			return fmt.Errorf("duplicate card %s at position %d", c, i+1)
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+func isValidCard(c Card) bool {
+	if c.rank == jokerA || c.rank == jokerB {
+		return c.suit == 0
+	}
+	if c.rank < ace || c.rank > king {
+		return false
+	}
+	switch c.suit {
+	case Clubs, Diamonds, Hearts, Spades:
+		return true
+	}
+	return false
+}
+
+var suitCodes = map[suit]string{
+	Clubs:    "C",
+	Diamonds: "D",
+	Hearts:   "H",
+	Spades:   "S",
+}
+
+// Code returns the short code of the card as used by Export:
+// the suit letter followed by the rank number, e.g. "C1" for the ace of clubs,
+// or "JA" and "JB" for the jokers.
+func (c Card) Code() string {
+	if c.IsJokerA() {
+		return "JA"
+	}
+	if c.IsJokerB() {
+		return "JB"
+	}
+	return fmt.Sprintf("%s%d", suitCodes[c.suit], c.rank)
+}
+
+// Export returns the deck as a comma separated sequence of card codes,
+// suitable for importing with ParseDeck.
+func (d *Deck) Export() string {
+	codes := make([]string, len(d))
+	for i, c := range d {
+		codes[i] = c.Code()
+	}
+	return strings.Join(codes, ",")
+}
+
+// ParseDeck parses a deck in the format produced by Export.
+// Whitespace around the card codes is ignored and the codes are case-insensitive.
+// The resulting deck is validated.
+func ParseDeck(s string) (*Deck, error) {
+	codes := strings.Split(s, ",")
+	if len(codes) != len(Deck{}) {
+		return nil, fmt.Errorf("deck must contain %d cards, got %d", len(Deck{}), len(codes))
+	}
+	d := &Deck{}
+	for i, code := range codes {
+		c, err := parseCardCode(strings.ToUpper(strings.TrimSpace(code)))
+		if err != nil {
+			return nil, fmt.Errorf("position %d: %w", i+1, err)
+		}
+		d[i] = c
+	}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func parseCardCode(code string) (Card, error) {
+	switch code {
+	case "JA":
+		return Card{rank: jokerA}, nil
+	case "JB":
+		return Card{rank: jokerB}, nil
+	}
+	if len(code) < 2 {
+		return Card{}, fmt.Errorf("invalid card code %q", code)
+	}
+	for s, l := range suitCodes {
+		if code[:1] != l {
+			continue
+		}
+		r, err := strconv.Atoi(code[1:])
+		if err != nil || r < int(ace) || r > int(king) {
+			return Card{}, fmt.Errorf("invalid rank in card code %q", code)
+		}
+		return Card{suit: s, rank: rank(r)}, nil
+	}
+	return Card{}, fmt.Errorf("invalid suit in card code %q", code)
+}
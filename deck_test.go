@@ -0,0 +1,78 @@
+package solitaire
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DeckSuite struct {
+	suite.Suite
+}
+
+func (s *DeckSuite) TestExportInitialDeck() {
+	d := Deck{}
+	copy(d[:], initialDeck)
+	export := d.Export()
+	s.True(strings.HasPrefix(export, "C1,C2,C3,"), "Expected export to start with the clubs")
+	s.True(strings.HasSuffix(export, ",S13,JA,JB"), "Expected export to end with the jokers")
+}
+
+func (s *DeckSuite) TestParseDeckRoundTrip() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	parsed, err := ParseDeck(sol.deck.Export())
+	s.Require().NoError(err)
+	s.Equal(*sol.deck, *parsed)
+}
+
+func (s *DeckSuite) TestParseDeckLenient() {
+	d := Deck{}
+	copy(d[:], initialDeck)
+	parsed, err := ParseDeck(strings.ToLower(strings.ReplaceAll(d.Export(), ",", ", ")))
+	s.Require().NoError(err)
+	s.Equal(d, *parsed)
+}
+
+func (s *DeckSuite) TestParseDeckInvalid() {
+	d := Deck{}
+	copy(d[:], initialDeck)
+	valid := d.Export()
+	testCases := []struct {
+		desc  string
+		input string
+	}{
+		{desc: "empty", input: ""},
+		{desc: "too few cards", input: "C1,C2,C3"},
+		{desc: "duplicate card", input: strings.Replace(valid, "C2,", "C1,", 1)},
+		{desc: "duplicate joker", input: strings.Replace(valid, "JA", "JB", 1)},
+		{desc: "invalid rank", input: strings.Replace(valid, "C2,", "C14,", 1)},
+		{desc: "invalid suit", input: strings.Replace(valid, "C2,", "X2,", 1)},
+	}
+	for _, tC := range testCases {
+		s.Run(tC.desc, func() {
+			_, err := ParseDeck(tC.input)
+			s.Error(err)
+		})
+	}
+}
+
+func (s *DeckSuite) TestValidate() {
+	d := Deck{}
+	copy(d[:], initialDeck)
+	s.NoError(d.Validate())
+	d[0] = Card{}
+	s.Error(d.Validate(), "Expected error for zero card")
+}
+
+func (s *DeckSuite) TestOutput() {
+	d := Deck{}
+	copy(d[:], initialDeck)
+	// The ace of clubs is on top, so the output card is the second card.
+	s.Equal(2, d.Output())
+}
+
+func TestDeck(t *testing.T) {
+	suite.Run(t, new(DeckSuite))
+}
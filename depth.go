@@ -0,0 +1,109 @@
+package solitaire
+
+import (
+	"math"
+	"sort"
+)
+
+// DepthMatch describes an alignment of two ciphertexts that looks like the
+// two were encrypted with the same keystream, i.e. that they are "in depth".
+//
+// For two ciphertexts in depth, the differences of their letters are the
+// differences of the plaintext letters, because the keystream cancels out.
+// These differences are distributed like the differences of natural language.
+// Most of the deviation from random letters is in the share of zero differences,
+// i.e. of coinciding letters, which is about 0.066 for English and German
+// compared to 1/26 for random letters. The other differences are close to
+// uniform, so the index of coincidence of the differences of texts in depth is
+// only slightly above 1/26, and the rate of coinciding letters is what
+// FindDepth uses to flag alignments.
+type DepthMatch struct {
+	// First and Second are the indices of the ciphertexts.
+	First, Second int
+	// Offset is the position in the first ciphertext at which the second
+	// ciphertext starts. It is negative if the second ciphertext starts before
+	// the first one.
+	Offset int
+	// Overlap is the number of letters the two ciphertexts share at Offset.
+	Overlap int
+	// IoC is the index of coincidence of the letter differences.
+	IoC float64
+	// IoCP is the probability of a difference distribution at least as far
+	// from uniform as the observed one, for ciphertexts not in depth.
+	IoCP float64
+	// Kappa is the fraction of positions where the letters are equal,
+	// i.e. where the difference is zero.
+	Kappa float64
+	// KappaZ is the z-score of Kappa against random letters.
+	KappaZ float64
+}
+
+// FindDepth slides each pair of ciphertexts against each other and computes the
+// statistics of their letter differences for every offset with an overlap of
+// at least minOverlap letters.
+// Alignments for which KappaZ, the z-score of the rate of coinciding letters,
+// reaches threshold are returned, best matches first.
+// The threshold is not applied to the index of coincidence: its chi-square
+// test spreads the signal, which sits in the zero differences, over 25 degrees
+// of freedom, and needs about twice the overlap to tell texts in depth from
+// random ones. IoC and IoCP are reported for a second opinion only.
+// Non-letters in the ciphertexts are ignored.
+func FindDepth(ciphertexts [][]byte, minOverlap int, threshold float64) []DepthMatch {
+	cleaned := make([][]byte, len(ciphertexts))
+	for i, ct := range ciphertexts {
+		cleaned[i] = normalizeCleartext(ct)
+	}
+
+	matches := make([]DepthMatch, 0)
+	for i := 0; i < len(cleaned); i++ {
+		for j := i + 1; j < len(cleaned); j++ {
+			a, b := cleaned[i], cleaned[j]
+			for offset := -(len(b) - minOverlap); offset <= len(a)-minOverlap; offset++ {
+				m := compareInDepth(a, b, offset)
+				if m.Overlap < minOverlap || m.Overlap < 2 {
+					continue
+				}
+				if m.KappaZ >= threshold {
+					m.First, m.Second = i, j
+					matches = append(matches, m)
+				}
+			}
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].KappaZ > matches[j].KappaZ
+	})
+	return matches
+}
+
+// compareInDepth computes the statistics of the letter differences of a and b,
+// with b starting at offset in a.
+func compareInDepth(a, b []byte, offset int) DepthMatch {
+	m := DepthMatch{Offset: offset}
+	var differences [26]int
+	for i := max(0, offset); i < len(a) && i-offset < len(b); i++ {
+		d := (int(a[i]) - int(b[i-offset]) + 26) % 26
+		differences[d]++
+		m.Overlap++
+	}
+	n := float64(m.Overlap)
+	if m.Overlap < 2 {
+		return m
+	}
+
+	coincidences := 0.0
+	for _, f := range differences {
+		coincidences += float64(f) * float64(f-1)
+	}
+	const k = 26.0
+	m.IoC = coincidences / (n * (n - 1))
+	// The chi-square statistic of the differences against a uniform
+	// distribution is a linear function of the index of coincidence.
+	chiSquare := k*(n-1)*m.IoC + k - n
+	m.IoCP = chiSquareSurvival(chiSquare, 25)
+
+	p := 1 / k
+	m.Kappa = float64(differences[0]) / n
+	m.KappaZ = (float64(differences[0]) - n*p) / math.Sqrt(n*p*(1-p))
+	return m
+}
@@ -0,0 +1,89 @@
+package solitaire
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DepthSuite struct {
+	suite.Suite
+}
+
+const (
+	depthPlaintextA = "It was the best of times, it was the worst of times, it was the age of wisdom, " +
+		"it was the age of foolishness, it was the epoch of belief, it was the epoch of incredulity, " +
+		"it was the season of light, it was the season of darkness, it was the spring of hope, " +
+		"it was the winter of despair, we had everything before us, we had nothing before us, " +
+		"we were all going direct to heaven, we were all going direct the other way. In short, " +
+		"the period was so far like the present period, that some of its noisiest authorities " +
+		"insisted on its being received, for good or for evil, in the superlative degree of " +
+		"comparison only. There were a king with a large jaw and a queen with a plain face, on " +
+		"the throne of England; there were a king with a large jaw and a queen with a fair face, " +
+		"on the throne of France. In both countries it was clearer than crystal to the lords of " +
+		"the State preserves of loaves and fishes, that things in general were settled for ever."
+	depthPlaintextB = "Call me Ishmael. Some years ago, never mind how long precisely, having little or no " +
+		"money in my purse, and nothing particular to interest me on shore, I thought I would sail " +
+		"about a little and see the watery part of the world. It is a way I have of driving off the " +
+		"spleen and regulating the circulation. Whenever I find myself growing grim about the mouth; " +
+		"whenever it is a damp, drizzly November in my soul; whenever I find myself involuntarily " +
+		"pausing before coffin warehouses, and bringing up the rear of every funeral I meet; and " +
+		"especially whenever my hypos get such an upper hand of me, that it requires a strong moral " +
+		"principle to prevent me from deliberately stepping into the street, and methodically " +
+		"knocking people's hats off, then, I account it high time to get to sea as soon as I can. " +
+		"This is my substitute for pistol and ball. With a philosophical flourish Cato throws himself."
+)
+
+func (s *DepthSuite) encrypt(passphrase, plaintext string) []byte {
+	sol, err := New(WithPassphrase([]byte(passphrase)))
+	s.Require().NoError(err)
+	ct, err := sol.Encrypt([]byte(plaintext))
+	s.Require().NoError(err)
+	return ct
+}
+
+func (s *DepthSuite) TestFindDepthSameKey() {
+	cts := [][]byte{
+		s.encrypt("FOO", depthPlaintextA),
+		s.encrypt("CRYPTONOMICON", depthPlaintextB),
+		s.encrypt("FOO", depthPlaintextB),
+	}
+	matches := FindDepth(cts, 100, 4)
+	s.Require().NotEmpty(matches)
+	best := matches[0]
+	s.Equal(0, best.First)
+	s.Equal(2, best.Second)
+	s.Equal(0, best.Offset)
+	s.Greater(best.Kappa, 1.0/26)
+}
+
+func (s *DepthSuite) TestKappaBeatsIoC() {
+	a := normalizeCleartext(s.encrypt("FOO", depthPlaintextA))[:300]
+	b := normalizeCleartext(s.encrypt("FOO", depthPlaintextB))[:300]
+	m := compareInDepth(a, b, 0)
+	s.Greater(m.KappaZ, 3.0)
+	s.Greater(m.IoCP, 0.1, "The index of coincidence alone would miss the depth")
+}
+
+func (s *DepthSuite) TestFindDepthDifferentKeys() {
+	cts := [][]byte{
+		s.encrypt("FOO", depthPlaintextA),
+		s.encrypt("CRYPTONOMICON", depthPlaintextB),
+	}
+	matches := FindDepth(cts, 100, 4)
+	s.Empty(matches)
+}
+
+func (s *DepthSuite) TestCompareInDepth() {
+	m := compareInDepth([]byte("ABCDEFGH"), []byte("CDEF"), 2)
+	s.Equal(4, m.Overlap)
+	s.Equal(1.0, m.Kappa, "Expected all letters to coincide")
+	s.Equal(1.0, m.IoC)
+
+	m = compareInDepth([]byte("ABC"), []byte("XYZ"), -2)
+	s.Equal(1, m.Overlap)
+}
+
+func TestDepth(t *testing.T) {
+	suite.Run(t, new(DepthSuite))
+}
@@ -0,0 +1,181 @@
+package solitaire
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DiceShuffle shuffles a deck with the rolls of physical dice, e.g. in an
+// air-gapped key ceremony. It performs the Fisher-Yates shuffle of Shuffle,
+// drawing each index from as many rolls as needed to cover its range and
+// rejecting combinations that would bias the result. The same rolls always
+// give the same deck, so a deck can be audited by re-deriving it.
+type DiceShuffle struct {
+	sides int
+	deck  Deck
+	// pos is the position of the next card to swap, counting down to 0.
+	pos int
+	// pending holds the rolls of the current draw.
+	pending []int
+	rolls   []int
+}
+
+// NewDiceShuffle starts a shuffle of the initial deck with dice of the given
+// number of sides, e.g. 6, 10 or 20.
+func NewDiceShuffle(sides int) (*DiceShuffle, error) {
+	if sides < 2 || sides > 100 {
+		return nil, fmt.Errorf("dice must have between 2 and 100 sides, got %d", sides)
+	}
+	s := &DiceShuffle{sides: sides, pos: len(Deck{}) - 1}
+	copy(s.deck[:], initialDeck)
+	return s, nil
+}
+
+// drawSize returns the number of rolls for an index in [0, n) and the number
+// of roll combinations that are accepted, a multiple of n.
+func (s *DiceShuffle) drawSize(n int) (rolls, accepted int) {
+	combinations := 1
+	for combinations < n {
+		combinations *= s.sides
+		rolls++
+	}
+	return rolls, combinations / n * n
+}
+
+// Roll adds the value of a roll, from 1 to the number of sides.
+// Rolls after the shuffle is done are an error.
+func (s *DiceShuffle) Roll(value int) error {
+	if s.Done() {
+		return errors.New("the deck is already shuffled")
+	}
+	if value < 1 || value > s.sides {
+		return fmt.Errorf("roll must be between 1 and %d, got %d", s.sides, value)
+	}
+	s.rolls = append(s.rolls, value)
+	s.pending = append(s.pending, value-1)
+
+	n := s.pos + 1
+	k, accepted := s.drawSize(n)
+	if len(s.pending) < k {
+		return nil
+	}
+	v := 0
+	for _, r := range s.pending {
+		v = v*s.sides + r
+	}
+	s.pending = s.pending[:0]
+	if v >= accepted {
+		// Rejected, the draw is repeated.
+		return nil
+	}
+	j := v % n
+	s.deck[s.pos], s.deck[j] = s.deck[j], s.deck[s.pos]
+	s.pos--
+	return nil
+}
+
+// Done reports whether enough rolls were added to shuffle the deck.
+func (s *DiceShuffle) Done() bool {
+	return s.pos == 0
+}
+
+// Rolls returns the rolls added so far.
+func (s *DiceShuffle) Rolls() []int {
+	return append([]int{}, s.rolls...)
+}
+
+// Remaining returns the number of rolls still needed if no draw is rejected,
+// and the expected number of rolls including rejected draws.
+func (s *DiceShuffle) Remaining() (atLeast int, expected float64) {
+	for pos := s.pos; pos > 0; pos-- {
+		k, accepted := s.drawSize(pos + 1)
+		combinations := 1
+		for range k {
+			combinations *= s.sides
+		}
+		atLeast += k
+		expected += float64(k) * float64(combinations) / float64(accepted)
+	}
+	atLeast -= len(s.pending)
+	expected -= float64(len(s.pending))
+	return atLeast, expected
+}
+
+// Deck returns the shuffled deck. If the shuffle is not done, it returns an error.
+func (s *DiceShuffle) Deck() (*Deck, error) {
+	if !s.Done() {
+		atLeast, _ := s.Remaining()
+		return nil, fmt.Errorf("at least %d more rolls are needed", atLeast)
+	}
+	d := s.deck
+	return &d, nil
+}
+
+// DeckFromDice returns the deck shuffled with the rolls, see DiceShuffle.
+// All rolls must be used, so that a sequence of rolls maps to exactly one deck.
+func DeckFromDice(sides int, rolls []int) (*Deck, error) {
+	s, err := NewDiceShuffle(sides)
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range rolls {
+		if s.Done() {
+			return nil, fmt.Errorf("%d rolls left over after the deck was shuffled", len(rolls)-i)
+		}
+		if err := s.Roll(r); err != nil {
+			return nil, fmt.Errorf("roll %d: %w", i+1, err)
+		}
+	}
+	return s.Deck()
+}
+
+// ParseDiceRolls parses a sequence of rolls. For dice with up to ten sides,
+// every digit is a roll and 0 is read as 10, e.g. "3615" or "3 6 1 5".
+// For dice with more sides, the rolls are numbers separated by non-digits.
+func ParseDiceRolls(sides int, s string) ([]int, error) {
+	rolls := make([]int, 0)
+	if sides <= 10 {
+		for i, c := range s {
+			switch {
+			case c >= '0' && c <= '9':
+				r := int(c - '0')
+				if r == 0 {
+					r = 10
+				}
+				if r > sides {
+					return nil, fmt.Errorf("roll %q at offset %d is more than %d", c, i, sides)
+				}
+				rolls = append(rolls, r)
+			case unicode.IsSpace(c) || c == ',':
+			default:
+				return nil, fmt.Errorf("invalid character %q at offset %d", c, i)
+			}
+		}
+		return rolls, nil
+	}
+	for _, f := range strings.FieldsFunc(s, func(c rune) bool { return unicode.IsSpace(c) || c == ',' }) {
+		r, err := strconv.Atoi(f)
+		if err != nil || r < 1 || r > sides {
+			return nil, fmt.Errorf("invalid roll %q, must be between 1 and %d", f, sides)
+		}
+		rolls = append(rolls, r)
+	}
+	return rolls, nil
+}
+
+// FormatDiceRolls formats rolls so that ParseDiceRolls reads them back:
+// digits with 0 for 10 for dice with up to ten sides, numbers otherwise,
+// separated by spaces.
+func FormatDiceRolls(sides int, rolls []int) string {
+	strs := make([]string, len(rolls))
+	for i, r := range rolls {
+		if sides <= 10 {
+			r %= 10
+		}
+		strs[i] = strconv.Itoa(r)
+	}
+	return strings.Join(strs, " ")
+}
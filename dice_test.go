@@ -0,0 +1,150 @@
+package solitaire
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DiceSuite struct {
+	suite.Suite
+}
+
+// rollUntilDone rolls dice from rng until the shuffle is done.
+func rollUntilDone(s *DiceShuffle, sides int, rng *rand.Rand) {
+	for !s.Done() {
+		if err := s.Roll(rng.IntN(sides) + 1); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (s *DiceSuite) TestShuffle() {
+	for _, sides := range []int{6, 10, 20} {
+		s.Run(fmt.Sprintf("d%d", sides), func() {
+			ds, err := NewDiceShuffle(sides)
+			s.Require().NoError(err)
+			atLeast, expected := ds.Remaining()
+			s.Greater(atLeast, 0)
+			s.GreaterOrEqual(expected, float64(atLeast))
+
+			rollUntilDone(ds, sides, rand.New(rand.NewPCG(1, uint64(sides))))
+			d, err := ds.Deck()
+			s.Require().NoError(err)
+			s.NoError(d.Validate())
+			atLeast, expected = ds.Remaining()
+			s.Zero(atLeast)
+			s.Zero(expected)
+			s.Error(ds.Roll(1), "Rolls after the shuffle are an error")
+
+			again, err := DeckFromDice(sides, ds.Rolls())
+			s.Require().NoError(err)
+			s.Equal(*d, *again, "The same rolls give the same deck")
+		})
+	}
+}
+
+func (s *DiceSuite) TestUniform() {
+	rng := rand.New(rand.NewPCG(1, 2))
+	counts := make([]int, 54)
+	for range 5400 {
+		ds, err := NewDiceShuffle(6)
+		s.Require().NoError(err)
+		rollUntilDone(ds, 6, rng)
+		d, err := ds.Deck()
+		s.Require().NoError(err)
+		counts[d.find(initialDeck[0])]++
+	}
+	chi2 := 0.0
+	for _, c := range counts {
+		chi2 += math.Pow(float64(c)-100, 2) / 100
+	}
+	// The 99.9% quantile of the chi-squared distribution with 53 degrees of freedom.
+	s.Less(chi2, 90.0)
+}
+
+func (s *DiceSuite) TestRejection() {
+	ds, err := NewDiceShuffle(6)
+	s.Require().NoError(err)
+	// The first draw is an index in [0, 54) from three d6 rolls, and all 216
+	// combinations are accepted.
+	for _, r := range []int{1, 1, 2} {
+		s.Require().NoError(ds.Roll(r))
+	}
+	s.Equal(52, ds.pos)
+	s.Equal(initialDeck[1], ds.deck[53], "The card at index 1 is swapped to the bottom")
+
+	// The second draw is an index in [0, 53), so the last 4 combinations are rejected.
+	for _, r := range []int{6, 6, 6} {
+		s.Require().NoError(ds.Roll(r))
+	}
+	s.Equal(52, ds.pos, "The draw is rejected")
+	for _, r := range []int{1, 1, 1} {
+		s.Require().NoError(ds.Roll(r))
+	}
+	s.Equal(51, ds.pos)
+	s.Equal(initialDeck[0], ds.deck[52])
+}
+
+func (s *DiceSuite) TestDeckFromDiceInvalid() {
+	_, err := DeckFromDice(6, []int{1, 2, 3})
+	s.ErrorContains(err, "more rolls")
+	_, err = DeckFromDice(6, []int{7})
+	s.Error(err)
+	_, err = DeckFromDice(1, nil)
+	s.Error(err)
+
+	ds, err := NewDiceShuffle(20)
+	s.Require().NoError(err)
+	rollUntilDone(ds, 20, rand.New(rand.NewPCG(3, 4)))
+	_, err = DeckFromDice(20, append(ds.Rolls(), 1))
+	s.ErrorContains(err, "left over")
+}
+
+func (s *DiceSuite) TestParseDiceRolls() {
+	rolls, err := ParseDiceRolls(6, "36 15,2\n4")
+	s.NoError(err)
+	s.Equal([]int{3, 6, 1, 5, 2, 4}, rolls)
+	rolls, err = ParseDiceRolls(10, "0919")
+	s.NoError(err)
+	s.Equal([]int{10, 9, 1, 9}, rolls)
+	rolls, err = ParseDiceRolls(20, "20 1,13")
+	s.NoError(err)
+	s.Equal([]int{20, 1, 13}, rolls)
+
+	for _, tC := range []struct {
+		sides int
+		rolls string
+	}{{6, "7"}, {6, "0"}, {6, "3a"}, {20, "21"}, {20, "0"}, {20, "x"}} {
+		_, err := ParseDiceRolls(tC.sides, tC.rolls)
+		s.Error(err, tC.rolls)
+	}
+}
+
+func (s *DiceSuite) TestFormatDiceRolls() {
+	s.Equal("3 0 1", FormatDiceRolls(10, []int{3, 10, 1}))
+	s.Equal("20 10 1", FormatDiceRolls(20, []int{20, 10, 1}))
+
+	// The printed rolls re-derive the deck.
+	for i, sides := range []int{6, 10, 20} {
+		ds, err := NewDiceShuffle(sides)
+		s.Require().NoError(err)
+		rollUntilDone(ds, sides, rand.New(rand.NewPCG(uint64(i), 5)))
+		s.Require().Contains(ds.Rolls(), sides)
+		expected, err := ds.Deck()
+		s.Require().NoError(err)
+
+		rolls, err := ParseDiceRolls(sides, FormatDiceRolls(sides, ds.Rolls()))
+		s.Require().NoError(err)
+		d, err := DeckFromDice(sides, rolls)
+		s.Require().NoError(err)
+		s.Equal(*expected, *d, "d%d", sides)
+	}
+}
+
+func TestDice(t *testing.T) {
+	suite.Run(t, new(DiceSuite))
+}
@@ -0,0 +1,83 @@
+package solitaire
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// fingerprintKey is the passphrase with which a copy of the deck is keyed
+// for its fingerprint.
+const fingerprintKey = "FINGERPRINT"
+
+// fingerprintLength is the number of letters of a fingerprint.
+const fingerprintLength = 5
+
+// ErrFingerprintMismatch is returned when decrypting a message whose
+// fingerprint header does not match the deck.
+var ErrFingerprintMismatch = errors.New("fingerprint header does not match the deck")
+
+// Fingerprint returns a check string of five letters for the deck, so that
+// two stations can confirm that they set up the same deck without revealing
+// its order.
+//
+// A copy of the deck is keyed with the passphrase "FINGERPRINT" and the
+// first five keystream values are taken as letters, 1 and 27 being A. This can
+// be done by hand. Keying the copy first keeps the fingerprint apart from the
+// keystream of messages: it does not reveal the first letters of the
+// keystream, which would otherwise be used to encrypt every first message.
+// Like any five letters of keystream, it narrows down the deck for someone
+// trying to recover it, so treat it as confidential, not public, unless the
+// cost of WithFingerprintHeader, which sends it in clear, is accepted.
+func (d *Deck) Fingerprint() string {
+	c := *d
+	c.key([]byte(fingerprintKey))
+	s := &solitaire{deck: &c}
+	letters := make([]byte, fingerprintLength)
+	for i, k := range s.generateKeyStream(fingerprintLength) {
+		letters[i] = 'A' + byte((k-1)%26)
+	}
+	return string(letters)
+}
+
+// WithFingerprintHeader makes Encrypt and EncryptBinary prepend the
+// fingerprint of the deck as the first group of five letters of the
+// ciphertext. Decrypt, DecryptBinary and Repair then expect the header and
+// return ErrFingerprintMismatch if it is not the fingerprint of the deck,
+// e.g. because the stations set up different decks.
+// The fingerprint is the one of the deck after all options were applied.
+// The header cannot be combined with WithPreservedFormat.
+//
+// The header is sent in clear with every message. It links all messages
+// under the same deck for anyone listening, and hands them the five
+// keystream letters of the fingerprint to narrow down the deck. Use it only
+// if catching a wrong deck is worth that cost.
+func WithFingerprintHeader() SolitaireOption {
+	return func(s *solitaire) error {
+		s.fingerprintHeader = true
+		return nil
+	}
+}
+
+// addHeader prepends the fingerprint header to the ciphertext letters, if enabled.
+func (s *solitaire) addHeader(ct []byte) []byte {
+	if !s.fingerprintHeader {
+		return ct
+	}
+	return append([]byte(s.fingerprint), ct...)
+}
+
+// checkHeader strips the fingerprint header from the ciphertext letters, if
+// enabled, and checks it against the fingerprint of the deck.
+func (s *solitaire) checkHeader(ct []byte) ([]byte, error) {
+	if !s.fingerprintHeader {
+		return ct, nil
+	}
+	if len(ct) < fingerprintLength {
+		return nil, fmt.Errorf("ciphertext is too short for the fingerprint header")
+	}
+	if !bytes.Equal(bytes.ToUpper(ct[:fingerprintLength]), []byte(s.fingerprint)) {
+		return nil, fmt.Errorf("%w: got %s, expected %s", ErrFingerprintMismatch, ct[:fingerprintLength], s.fingerprint)
+	}
+	return ct[fingerprintLength:], nil
+}
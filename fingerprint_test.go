@@ -0,0 +1,92 @@
+package solitaire
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FingerprintSuite struct {
+	suite.Suite
+}
+
+func (s *FingerprintSuite) TestFingerprint() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	before := *sol.deck
+	fp := sol.deck.Fingerprint()
+	s.Regexp(`^[A-Z]{5}$`, fp)
+	s.Equal(before, *sol.deck, "The deck is not changed")
+	s.Equal(fp, sol.deck.Fingerprint())
+
+	// The fingerprint is the keystream of the deck keyed with FINGERPRINT.
+	keyed, err := New(WithDeck(&before))
+	s.Require().NoError(err)
+	keyed.deck.key([]byte("FINGERPRINT"))
+	ct, err := keyed.Encrypt([]byte("ZZZZZ"))
+	s.Require().NoError(err)
+	s.Equal(string(ct), fp)
+
+	// It differs from the first letters of the keystream.
+	sol, err = New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	ct, err = sol.Encrypt([]byte("ZZZZZ"))
+	s.Require().NoError(err)
+	s.NotEqual(string(ct), fp)
+
+	other, err := New(WithPassphrase([]byte("CRYPTONOMICOM")))
+	s.Require().NoError(err)
+	s.NotEqual(fp, other.deck.Fingerprint())
+}
+
+func (s *FingerprintSuite) TestHeader() {
+	opts := []SolitaireOption{WithPassphrase([]byte("CRYPTONOMICON")), WithFingerprintHeader()}
+	sol, err := New(opts...)
+	s.Require().NoError(err)
+	fp := sol.deck.Fingerprint()
+	ct, err := sol.Encrypt([]byte("SOLITAIRE"))
+	s.Require().NoError(err)
+	s.Equal(fp+" KIRAK SFJAN", string(ct), "The header precedes the ciphertext")
+	// The instance keeps the fingerprint of the deck as set up.
+	ct2, err := sol.Encrypt([]byte("SOLITAIRE"))
+	s.Require().NoError(err)
+	s.True(strings.HasPrefix(string(ct2), fp))
+
+	sol, err = New(opts...)
+	s.Require().NoError(err)
+	pt, err := sol.Decrypt(ct)
+	s.Require().NoError(err)
+	s.Equal("SOLIT AIREX", string(pt))
+
+	wrong, err := New(WithPassphrase([]byte("CRYPTONOMICOM")), WithFingerprintHeader())
+	s.Require().NoError(err)
+	_, err = wrong.Decrypt(ct)
+	s.ErrorIs(err, ErrFingerprintMismatch)
+	_, err = wrong.Repair(ct, 1)
+	s.ErrorIs(err, ErrFingerprintMismatch)
+	_, err = wrong.DecryptBinary(ct)
+	s.ErrorIs(err, ErrFingerprintMismatch)
+}
+
+func (s *FingerprintSuite) TestHeaderBinary() {
+	opts := []SolitaireOption{WithPassphrase([]byte("CRYPTONOMICON")), WithFingerprintHeader()}
+	sol, err := New(opts...)
+	s.Require().NoError(err)
+	ct, err := sol.EncryptBinary([]byte{1, 2, 3})
+	s.Require().NoError(err)
+	sol, err = New(opts...)
+	s.Require().NoError(err)
+	data, err := sol.DecryptBinary(ct)
+	s.Require().NoError(err)
+	s.Equal([]byte{1, 2, 3}, data)
+}
+
+func (s *FingerprintSuite) TestHeaderPreservedFormat() {
+	_, err := New(WithPassphrase([]byte("CRYPTONOMICON")), WithFingerprintHeader(), WithPreservedFormat())
+	s.Error(err)
+}
+
+func TestFingerprint(t *testing.T) {
+	suite.Run(t, new(FingerprintSuite))
+}
@@ -6,13 +6,14 @@ require (
 	github.com/alecthomas/kong v1.9.0
 	github.com/awnumar/memguard v0.22.5
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/crypto v0.16.0
+	golang.org/x/term v0.15.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/awnumar/memcall v0.2.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/crypto v0.16.0 // indirect
 	golang.org/x/sys v0.15.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
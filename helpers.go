@@ -12,6 +12,10 @@ const capitalOumlaut = 214
 const capitalUumlaut = 220
 const sharpS = 223
 
+// utf8Latin1Lead is the first byte of the UTF-8 encoding of the Latin-1
+// letters from U+00C0 to U+00FF, which include the umlauts and sharp S.
+const utf8Latin1Lead = 0xC3
+
 var mappings = map[byte][]byte{
 	'Ä': {'A', 'E'},
 	'Ö': {'O', 'E'},
@@ -59,6 +63,27 @@ func normalizeCleartext(plaintext []byte) []byte {
 	return normalized
 }
 
+// latin1Umlauts returns a copy of text in which the umlauts and sharp S
+// encoded as UTF-8 are replaced by their Latin-1 bytes, which
+// normalizeCleartext spells out.
+func latin1Umlauts(text []byte) []byte {
+	out := make([]byte, 0, len(text))
+	for i := 0; i < len(text); i++ {
+		b := text[i]
+		// The UTF-8 encoding is two bytes, the second holding the lower bits
+		// of the Latin-1 code.
+		if b == utf8Latin1Lead && i+1 < len(text) && text[i+1]&0xC0 == 0x80 {
+			if latin1 := 0xC0 | text[i+1]&0x3F; mappings[latin1] != nil {
+				out = append(out, latin1)
+				i++
+				continue
+			}
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
 var nonLetters = regexp.MustCompile(`[^\p{L}]+`)
 
 func padClearText(plaintext []byte) []byte {
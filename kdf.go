@@ -0,0 +1,153 @@
+package solitaire
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/awnumar/memguard"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20"
+)
+
+// KDFParams are the Argon2id parameters with which WithKDFPassphrase
+// stretches a passphrase. Both stations need the same parameters, including
+// the salt, to set up the same deck.
+type KDFParams struct {
+	Salt []byte
+	// Time is the number of passes over the memory.
+	Time uint32
+	// Memory is the memory in KiB.
+	Memory uint32
+	// Threads is the degree of parallelism.
+	Threads uint8
+}
+
+const (
+	// kdfSaltLength is the length of the salt of NewKDFParams.
+	kdfSaltLength = 16
+	// kdfMaxMemory is the largest accepted memory in KiB, 1 GiB.
+	kdfMaxMemory = 1 << 20
+	// kdfMaxTime is the largest accepted number of passes.
+	kdfMaxTime = 100
+)
+
+// NewKDFParams returns parameters with a random salt from crypto/rand and
+// the costs used for keyrings: 3 passes over 64 MiB with 4 threads.
+func NewKDFParams() (KDFParams, error) {
+	p := KDFParams{Salt: make([]byte, kdfSaltLength), Time: 3, Memory: 64 * 1024, Threads: 4}
+	if _, err := rand.Read(p.Salt); err != nil {
+		return KDFParams{}, err
+	}
+	return p, nil
+}
+
+// validate checks that the parameters are usable and not absurdly costly.
+func (p KDFParams) validate() error {
+	switch {
+	case len(p.Salt) < 8:
+		return errors.New("kdf salt must have at least 8 bytes")
+	case p.Time == 0 || p.Threads == 0:
+		return errors.New("kdf time and threads must be at least 1")
+	case p.Time > kdfMaxTime:
+		return fmt.Errorf("kdf time must not exceed %d passes", kdfMaxTime)
+	case p.Memory < 8*uint32(p.Threads) || p.Memory > kdfMaxMemory:
+		return fmt.Errorf("kdf memory must be between 8 KiB per thread and %d KiB", kdfMaxMemory)
+	}
+	return nil
+}
+
+// String encodes the parameters like the PHC string format of Argon2 hashes,
+// without the hash: argon2id$v=19$m=65536,t=3,p=4$SALT, the salt being in
+// unpadded base64.
+func (p KDFParams) String() string {
+	return fmt.Sprintf("argon2id$v=%d$m=%d,t=%d,p=%d$%s", argon2.Version, p.Memory, p.Time, p.Threads, base64.RawStdEncoding.EncodeToString(p.Salt))
+}
+
+// ParseKDFParams parses parameters in the format of KDFParams.String.
+func ParseKDFParams(s string) (KDFParams, error) {
+	var p KDFParams
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(s), "$"), "$")
+	if len(parts) != 4 || parts[0] != "argon2id" {
+		return p, fmt.Errorf("kdf parameters must have the form argon2id$v=%d$m=MEMORY,t=TIME,p=THREADS$SALT", argon2.Version)
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[1], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, fmt.Errorf("unsupported argon2 version %q", parts[1])
+	}
+	if _, err := fmt.Sscanf(parts[2], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads); err != nil {
+		return p, fmt.Errorf("invalid kdf costs %q: %w", parts[2], err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return p, fmt.Errorf("invalid kdf salt: %w", err)
+	}
+	p.Salt = salt
+	return p, p.validate()
+}
+
+// DeckFromKDFPassphrase derives a uniformly random deck from the passphrase.
+// The passphrase is stretched with Argon2id into a key for ChaCha20, whose
+// keystream drives the Fisher-Yates shuffle of Shuffle on the initial deck.
+//
+// Unlike the keying of WithPassphrase, any bytes are used, e.g. UTF-8 text
+// in any script, and the deck can carry the full entropy of DeckEntropy.
+// The bytes are used as given, so text that looks the same but is encoded
+// differently, like composed and decomposed umlauts, gives different decks.
+// This cannot be done by hand.
+func DeckFromKDFPassphrase(passphrase []byte, p KDFParams) (*Deck, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	key := argon2.IDKey(passphrase, p.Salt, p.Time, p.Memory, p.Threads, chacha20.KeySize)
+	defer memguard.WipeBytes(key)
+	stream, err := chacha20.NewUnauthenticatedCipher(key, make([]byte, chacha20.NonceSize))
+	if err != nil {
+		return nil, err
+	}
+	return randomDeck(&keystreamReader{stream})
+}
+
+// keystreamReader reads the keystream of a ChaCha20 cipher.
+type keystreamReader struct {
+	stream *chacha20.Cipher
+}
+
+func (r *keystreamReader) Read(p []byte) (int, error) {
+	clear(p)
+	r.stream.XORKeyStream(p, p)
+	return len(p), nil
+}
+
+// WithKDFPassphrase sets the deck derived from the passphrase with
+// DeckFromKDFPassphrase. It is meant for computers only; use WithPassphrase
+// for decks keyed by hand.
+func WithKDFPassphrase(passphrase []byte, p KDFParams) SolitaireOption {
+	return func(s *solitaire) error {
+		d, err := DeckFromKDFPassphrase(passphrase, p)
+		if err != nil {
+			return err
+		}
+		s.deck = d
+		return nil
+	}
+}
+
+// WithKDFPassphraseFromEnclave is like WithKDFPassphrase, but takes the
+// passphrase from a memguard.Enclave.
+// If the memguard.Enclave cannot be opened, WithKDFPassphraseFromEnclave will panic.
+func WithKDFPassphraseFromEnclave(passphrase *memguard.Enclave, p KDFParams) SolitaireOption {
+	return func(s *solitaire) error {
+		if passphrase == nil {
+			return fmt.Errorf("passphrase is required")
+		}
+		buf, err := passphrase.Open()
+		if err != nil {
+			memguard.SafePanic(err)
+		}
+		defer buf.Destroy()
+		return WithKDFPassphrase(buf.Bytes(), p)(s)
+	}
+}
@@ -0,0 +1,96 @@
+package solitaire
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type KDFSuite struct {
+	suite.Suite
+}
+
+// cheapKDF are parameters that keep the tests fast.
+var cheapKDF = KDFParams{Salt: []byte("solitaire salt"), Time: 1, Memory: 64, Threads: 1}
+
+func (s *KDFSuite) TestDeckFromKDFPassphrase() {
+	d, err := DeckFromKDFPassphrase([]byte("Schöne Grüße, 世界!"), cheapKDF)
+	s.Require().NoError(err)
+	s.NoError(d.Validate())
+	again, err := DeckFromKDFPassphrase([]byte("Schöne Grüße, 世界!"), cheapKDF)
+	s.Require().NoError(err)
+	s.Equal(*d, *again)
+
+	other, err := DeckFromKDFPassphrase([]byte("Schöne Grüsse, 世界!"), cheapKDF)
+	s.Require().NoError(err)
+	s.NotEqual(*d, *other)
+
+	salted := cheapKDF
+	salted.Salt = []byte("another salt")
+	other, err = DeckFromKDFPassphrase([]byte("Schöne Grüße, 世界!"), salted)
+	s.Require().NoError(err)
+	s.NotEqual(*d, *other)
+}
+
+func (s *KDFSuite) TestKnownDeck() {
+	// The derivation must not change, or receivers could no longer
+	// reproduce the decks of earlier messages.
+	d, err := DeckFromKDFPassphrase([]byte("CRYPTONOMICON"), cheapKDF)
+	s.Require().NoError(err)
+	s.Equal("C9,JB,S8,S2,S6,H9,C2,C5,D1,D11,H4,C6,S5,D5,C7,C8,D7,S3,D9,S7,S9,H7,S11,H10,C4,H13,D2,C10,D12,S4,C11,D13,D6,S13,D8,H6,H3,C3,S12,C13,C1,H12,JA,H1,D4,H5,H11,S1,S10,H2,D10,C12,H8,D3", d.Export())
+}
+
+func (s *KDFSuite) TestWithKDFPassphrase() {
+	sol, err := New(WithKDFPassphrase([]byte("CRYPTONOMICON"), cheapKDF))
+	s.Require().NoError(err)
+	ct, err := sol.Encrypt([]byte("SOLITAIRE"))
+	s.Require().NoError(err)
+
+	sol, err = New(WithKDFPassphrase([]byte("CRYPTONOMICON"), cheapKDF))
+	s.Require().NoError(err)
+	pt, err := sol.Decrypt(ct)
+	s.Require().NoError(err)
+	s.Equal("SOLIT AIREX", string(pt))
+
+	_, err = New(WithKDFPassphrase([]byte("CRYPTONOMICON"), KDFParams{}))
+	s.Error(err)
+}
+
+func (s *KDFSuite) TestParams() {
+	p, err := NewKDFParams()
+	s.Require().NoError(err)
+	s.Len(p.Salt, kdfSaltLength)
+	s.Regexp(`^argon2id\$v=19\$m=65536,t=3,p=4\$[A-Za-z0-9+/]{22}$`, p.String())
+	parsed, err := ParseKDFParams(p.String())
+	s.Require().NoError(err)
+	s.Equal(p, parsed)
+
+	other, err := NewKDFParams()
+	s.Require().NoError(err)
+	s.NotEqual(p.Salt, other.Salt)
+
+	parsed, err = ParseKDFParams("$argon2id$v=19$m=64,t=1,p=1$c29saXRhaXJlIHNhbHQ")
+	s.Require().NoError(err)
+	s.Equal(cheapKDF, parsed)
+}
+
+func (s *KDFSuite) TestParseParamsInvalid() {
+	for _, in := range []string{
+		"",
+		"argon2i$v=19$m=64,t=1,p=1$c29saXRhaXJlIHNhbHQ",
+		"argon2id$v=16$m=64,t=1,p=1$c29saXRhaXJlIHNhbHQ",
+		"argon2id$v=19$m=64,t=1$c29saXRhaXJlIHNhbHQ",
+		"argon2id$v=19$m=64,t=0,p=1$c29saXRhaXJlIHNhbHQ",
+		"argon2id$v=19$m=4194304,t=1,p=1$c29saXRhaXJlIHNhbHQ",
+		"argon2id$v=19$m=64,t=4294967295,p=1$c29saXRhaXJlIHNhbHQ",
+		"argon2id$v=19$m=64,t=1,p=1$c29sa",
+		"argon2id$v=19$m=64,t=1,p=1$!!!",
+	} {
+		_, err := ParseKDFParams(in)
+		s.Error(err, in)
+	}
+}
+
+func TestKDF(t *testing.T) {
+	suite.Run(t, new(KDFSuite))
+}
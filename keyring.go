@@ -0,0 +1,155 @@
+package solitaire
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Keyring is a set of named decks that is stored encrypted with a password.
+type Keyring struct {
+	decks map[string]Deck
+}
+
+// sealedKeyring is the file format of a sealed keyring. The decks are
+// encrypted with XChaCha20-Poly1305 under a key derived from the password
+// with Argon2id.
+type sealedKeyring struct {
+	Version int `json:"version"`
+	KDF     struct {
+		Salt    []byte `json:"salt"`
+		Time    uint32 `json:"time"`
+		Memory  uint32 `json:"memory"`
+		Threads uint8  `json:"threads"`
+	} `json:"kdf"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+const keyringVersion = 1
+
+// ErrKeyringPassword is returned by OpenKeyring if the password is wrong or
+// the keyring has been tampered with.
+var ErrKeyringPassword = errors.New("wrong keyring password or corrupted keyring")
+
+// NewKeyring returns an empty keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{decks: make(map[string]Deck)}
+}
+
+// Add adds the deck under the name. If the name exists, it returns an error.
+func (k *Keyring) Add(name string, d *Deck) error {
+	if name == "" {
+		return errors.New("deck name must not be empty")
+	}
+	if _, ok := k.decks[name]; ok {
+		return fmt.Errorf("deck %q already exists in keyring", name)
+	}
+	if err := d.Validate(); err != nil {
+		return fmt.Errorf("invalid deck: %w", err)
+	}
+	k.decks[name] = *d
+	return nil
+}
+
+// Deck returns a copy of the deck stored under the name.
+func (k *Keyring) Deck(name string) (*Deck, error) {
+	d, ok := k.decks[name]
+	if !ok {
+		return nil, fmt.Errorf("deck %q not found in keyring", name)
+	}
+	return &d, nil
+}
+
+// Names returns the names of the decks, sorted.
+func (k *Keyring) Names() []string {
+	names := make([]string, 0, len(k.decks))
+	for name := range k.decks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Seal encrypts the keyring with the password and returns it in its file format.
+func (k *Keyring) Seal(password []byte) ([]byte, error) {
+	if len(password) == 0 {
+		return nil, errors.New("keyring password must not be empty")
+	}
+	exported := make(map[string]string, len(k.decks))
+	for name, d := range k.decks {
+		exported[name] = d.Export()
+	}
+	plaintext, err := json.Marshal(exported)
+	if err != nil {
+		return nil, err
+	}
+
+	var sk sealedKeyring
+	sk.Version = keyringVersion
+	sk.KDF.Salt = make([]byte, 16)
+	sk.KDF.Time, sk.KDF.Memory, sk.KDF.Threads = 3, 64*1024, 4
+	sk.Nonce = make([]byte, chacha20poly1305.NonceSizeX)
+	if _, err := rand.Read(sk.KDF.Salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(sk.Nonce); err != nil {
+		return nil, err
+	}
+	aead, err := sk.aead(password)
+	if err != nil {
+		return nil, err
+	}
+	sk.Ciphertext = aead.Seal(nil, sk.Nonce, plaintext, nil)
+	return json.MarshalIndent(sk, "", "  ")
+}
+
+// OpenKeyring decrypts a keyring sealed by Seal with the password.
+func OpenKeyring(data, password []byte) (*Keyring, error) {
+	var sk sealedKeyring
+	if err := json.Unmarshal(data, &sk); err != nil {
+		return nil, fmt.Errorf("invalid keyring: %w", err)
+	}
+	if sk.Version != keyringVersion {
+		return nil, fmt.Errorf("unsupported keyring version %d", sk.Version)
+	}
+	if len(sk.Nonce) != chacha20poly1305.NonceSizeX {
+		return nil, errors.New("invalid keyring: bad nonce")
+	}
+	params := KDFParams{Salt: sk.KDF.Salt, Time: sk.KDF.Time, Memory: sk.KDF.Memory, Threads: sk.KDF.Threads}
+	if err := params.validate(); err != nil {
+		return nil, fmt.Errorf("invalid keyring: %w", err)
+	}
+	aead, err := sk.aead(password)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, sk.Nonce, sk.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrKeyringPassword
+	}
+	var exported map[string]string
+	if err := json.Unmarshal(plaintext, &exported); err != nil {
+		return nil, fmt.Errorf("invalid keyring: %w", err)
+	}
+	k := NewKeyring()
+	for name, e := range exported {
+		d, err := ParseDeck(e)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deck %q in keyring: %w", name, err)
+		}
+		k.decks[name] = *d
+	}
+	return k, nil
+}
+
+func (sk *sealedKeyring) aead(password []byte) (cipher.AEAD, error) {
+	key := argon2.IDKey(password, sk.KDF.Salt, sk.KDF.Time, sk.KDF.Memory, sk.KDF.Threads, chacha20poly1305.KeySize)
+	return chacha20poly1305.NewX(key)
+}
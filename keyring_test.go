@@ -0,0 +1,107 @@
+package solitaire
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type KeyringSuite struct {
+	suite.Suite
+}
+
+func (s *KeyringSuite) TestDeckEntropy() {
+	lg, _ := math.Lgamma(55)
+	s.InDelta(lg/math.Ln2, DeckEntropy, 1e-9)
+}
+
+func (s *KeyringSuite) TestRandomDeck() {
+	d, err := RandomDeck()
+	s.Require().NoError(err)
+	s.NoError(d.Validate())
+	other, err := RandomDeck()
+	s.Require().NoError(err)
+	s.NotEqual(*d, *other)
+}
+
+func (s *KeyringSuite) TestRandomDeckUniform() {
+	// Count the positions of the ace of clubs over many shuffles from a
+	// deterministic reader.
+	r := rand.NewChaCha8([32]byte{})
+	counts := make([]int, 54)
+	for range 5400 {
+		d, err := randomDeck(r)
+		s.Require().NoError(err)
+		counts[d.find(initialDeck[0])]++
+	}
+	expected := 100.0
+	chi2 := 0.0
+	for _, c := range counts {
+		chi2 += math.Pow(float64(c)-expected, 2) / expected
+	}
+	// The 99.9% quantile of the chi-squared distribution with 53 degrees of freedom.
+	s.Less(chi2, 90.0)
+}
+
+func (s *KeyringSuite) TestRandomDeckReaderError() {
+	_, err := randomDeck(bytes.NewReader(make([]byte, 100)))
+	s.Error(err)
+}
+
+func (s *KeyringSuite) TestSealAndOpen() {
+	k := NewKeyring()
+	d, err := RandomDeck()
+	s.Require().NoError(err)
+	s.Require().NoError(k.Add("team", d))
+	s.Error(k.Add("team", d), "Names are unique")
+	s.Error(k.Add("", d))
+	s.Error(k.Add("invalid", &Deck{}))
+
+	sealed, err := k.Seal([]byte("correct horse"))
+	s.Require().NoError(err)
+	s.NotContains(string(sealed), d.Export())
+
+	opened, err := OpenKeyring(sealed, []byte("correct horse"))
+	s.Require().NoError(err)
+	s.Equal([]string{"team"}, opened.Names())
+	got, err := opened.Deck("team")
+	s.Require().NoError(err)
+	s.Equal(*d, *got)
+	_, err = opened.Deck("other")
+	s.Error(err)
+
+	_, err = OpenKeyring(sealed, []byte("wrong horse"))
+	s.ErrorIs(err, ErrKeyringPassword)
+	_, err = k.Seal(nil)
+	s.Error(err)
+}
+
+func (s *KeyringSuite) TestOpenInvalid() {
+	sealed, err := NewKeyring().Seal([]byte("pw"))
+	s.Require().NoError(err)
+	var sk map[string]any
+	s.Require().NoError(json.Unmarshal(sealed, &sk))
+	sk["version"] = 2
+	changed, err := json.Marshal(sk)
+	s.Require().NoError(err)
+	_, err = OpenKeyring(changed, []byte("pw"))
+	s.Error(err)
+
+	_, err = OpenKeyring([]byte("{"), []byte("pw"))
+	s.Error(err)
+
+	sk["version"] = keyringVersion
+	sk["kdf"].(map[string]any)["time"] = math.MaxUint32
+	changed, err = json.Marshal(sk)
+	s.Require().NoError(err)
+	_, err = OpenKeyring(changed, []byte("pw"))
+	s.ErrorContains(err, "kdf time", "The costs are checked before deriving the key")
+}
+
+func TestKeyring(t *testing.T) {
+	suite.Run(t, new(KeyringSuite))
+}
@@ -90,7 +90,7 @@ func (t defaultAlphabet) Char(index int) byte {
 	if index == 0 {
 		index = 26
 	}
-	return alphabet[index-1]
+	return t[index-1]
 }
 
 var alphabet = defaultAlphabet{
@@ -98,6 +98,27 @@ var alphabet = defaultAlphabet{
 	'K', 'L', 'M', 'N', 'O', 'P', 'Q', 'R', 'S', 'T',
 	'U', 'V', 'W', 'X', 'Y', 'Z'}
 
+// keyedAlphabet returns a mixed alphabet that starts with the letters of the
+// keyword in their first occurrence, followed by the remaining letters in
+// their usual order. The keyword is normalized like a cleartext.
+func keyedAlphabet(keyword []byte) (defaultAlphabet, error) {
+	var t defaultAlphabet
+	letters := normalizeCleartext(keyword)
+	if len(letters) == 0 {
+		return t, fmt.Errorf("alphabet key must contain letters")
+	}
+	var used [26]bool
+	n := 0
+	for _, c := range append(letters, alphabet[:]...) {
+		if i := alphabet.Index(c); !used[i] {
+			used[i] = true
+			t[n] = c
+			n++
+		}
+	}
+	return t, nil
+}
+
 type suit int
 
 const (
@@ -0,0 +1,203 @@
+package solitaire
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+//go:embed data/quadgrams_*.txt
+var quadgramData embed.FS
+
+// quadgramCount is the number of possible quadgrams of the letters A to Z.
+const quadgramCount = 26 * 26 * 26 * 26
+
+// Quadgrams is a language model holding the log-probabilities of letter quadgrams.
+type Quadgrams struct {
+	logProb []float32
+	// typical is the score of typical text in the language.
+	typical float64
+	// random is the expected score of uniformly random letters.
+	random float64
+}
+
+// typicalFraction places the score of typical text between the score of
+// random letters and the entropy of the model. Real text does not reach the
+// entropy, because it contains quadgrams spanning word boundaries, which are
+// rare or missing in tables built from words.
+const typicalFraction = 0.6
+
+// LoadQuadgrams reads a quadgram table with one quadgram per line, followed by
+// its count, separated by whitespace, e.g. "TION 13168375".
+// Quadgrams not listed in the table get a probability below that of the rarest
+// listed quadgram.
+func LoadQuadgrams(r io.Reader) (*Quadgrams, error) {
+	counts := make(map[int]float64)
+	total, minCount := 0.0, math.Inf(1)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := bytes.Fields(scanner.Bytes())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected quadgram and count", line)
+		}
+		letters := normalizeCleartext(fields[0])
+		if len(letters) != 4 || len(fields[0]) != 4 {
+			return nil, fmt.Errorf("line %d: invalid quadgram %q", line, fields[0])
+		}
+		count, err := strconv.ParseFloat(string(fields[1]), 64)
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("line %d: invalid count %q", line, fields[1])
+		}
+		counts[quadgramIndex(letters)] += count
+		total += count
+		minCount = math.Min(minCount, count)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(counts) == 0 {
+		return nil, fmt.Errorf("quadgram table is empty")
+	}
+
+	floor := math.Log10(minCount / 10 / total)
+	q := &Quadgrams{logProb: make([]float32, quadgramCount)}
+	for i := range q.logProb {
+		q.logProb[i] = float32(floor)
+	}
+	sum := float64(quadgramCount-len(counts)) * floor
+	entropy := 0.0
+	for i, c := range counts {
+		p := c / total
+		q.logProb[i] = float32(math.Log10(p))
+		entropy += p * math.Log10(p)
+		sum += math.Log10(p)
+	}
+	q.random = sum / quadgramCount
+	q.typical = q.random + typicalFraction*(entropy-q.random)
+	return q, nil
+}
+
+func quadgramIndex(letters []byte) int {
+	return ((int(letters[0]-'A')*26+int(letters[1]-'A'))*26+int(letters[2]-'A'))*26 + int(letters[3]-'A')
+}
+
+// Score returns the mean log10-probability of the quadgrams in the text
+// and the number of quadgrams scored. The text is normalized like a cleartext.
+// If the text has fewer than four letters, the score is zero.
+func (q *Quadgrams) Score(text []byte) (float64, int) {
+	letters := normalizeCleartext(text)
+	n := len(letters) - 3
+	if n <= 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		sum += float64(q.logProb[quadgramIndex(letters[i:i+4])])
+	}
+	return sum / float64(n), n
+}
+
+// Confidence maps a score to a value between 0 for uniformly random letters
+// and 1 for typical text in the language of the model.
+func (q *Quadgrams) Confidence(score float64) float64 {
+	c := (score - q.random) / (q.typical - q.random)
+	return math.Max(0, math.Min(1, c))
+}
+
+var (
+	languagesMu sync.RWMutex
+	languages   = make(map[string]*Quadgrams)
+	loadBuiltin sync.Once
+)
+
+// RegisterLanguage makes the quadgram model available under the given name
+// to Score and Language. A model registered under the name of an existing
+// model replaces it. The models for English ("en") and German ("de") are built in.
+func RegisterLanguage(name string, q *Quadgrams) {
+	loadBuiltinLanguages()
+	languagesMu.Lock()
+	defer languagesMu.Unlock()
+	languages[name] = q
+}
+
+// Language returns the quadgram model registered under name, or nil.
+func Language(name string) *Quadgrams {
+	loadBuiltinLanguages()
+	languagesMu.RLock()
+	defer languagesMu.RUnlock()
+	return languages[name]
+}
+
+// Languages returns the names of the registered quadgram models, sorted.
+func Languages() []string {
+	loadBuiltinLanguages()
+	languagesMu.RLock()
+	defer languagesMu.RUnlock()
+	names := make([]string, 0, len(languages))
+	for name := range languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func loadBuiltinLanguages() {
+	loadBuiltin.Do(func() {
+		for _, name := range []string{"en", "de"} {
+			f, err := quadgramData.Open("data/quadgrams_" + name + ".txt")
+			if err != nil {
+				panic(err)
+			}
+			q, err := LoadQuadgrams(f)
+			f.Close()
+			if err != nil {
+				// The embedded tables are part of the package, so this is a programming error.
+				panic(fmt.Sprintf("invalid embedded quadgram table %s: %s", name, err))
+			}
+			languagesMu.Lock()
+			languages[name] = q
+			languagesMu.Unlock()
+		}
+	})
+}
+
+// Assessment is the result of scoring a text against the registered languages.
+type Assessment struct {
+	// Language is the name of the best matching language model.
+	Language string
+	// Score is the mean log10-probability of the quadgrams in the text
+	// under the model of Language.
+	Score float64
+	// Confidence is the score mapped to a value between 0 for random letters
+	// and 1 for typical text in Language.
+	Confidence float64
+	// Quadgrams is the number of quadgrams scored. Short texts give unreliable results.
+	Quadgrams int
+}
+
+// Score scores the text against all registered language models and returns the
+// assessment of the model with the highest confidence. It can be used to tell
+// a correct decryption from the garbage produced by a wrong key.
+func Score(text []byte) Assessment {
+	best := Assessment{}
+	for _, name := range Languages() {
+		q := Language(name)
+		score, n := q.Score(text)
+		if n == 0 {
+			return best
+		}
+		if c := q.Confidence(score); best.Language == "" || c > best.Confidence {
+			best = Assessment{Language: name, Score: score, Confidence: c, Quadgrams: n}
+		}
+	}
+	return best
+}
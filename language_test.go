@@ -0,0 +1,98 @@
+package solitaire
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LanguageSuite struct {
+	suite.Suite
+}
+
+func (s *LanguageSuite) TestBuiltinLanguages() {
+	s.Equal([]string{"de", "en"}, Languages())
+	s.NotNil(Language("en"))
+	s.NotNil(Language("de"))
+	s.Nil(Language("xx"))
+}
+
+func (s *LanguageSuite) TestScore() {
+	testCases := []struct {
+		desc     string
+		text     string
+		language string
+		natural  bool
+	}{
+		{desc: "English", text: depthPlaintextA, language: "en", natural: true},
+		{desc: "English decryption", text: "MEETM EATTH EBRID GEATN OONXX", language: "en", natural: true},
+		{
+			desc:     "German",
+			text:     "Es war einmal ein kleines Mädchen, das hatte eine Großmutter, die wohnte draußen im Wald.",
+			language: "de",
+			natural:  true,
+		},
+		{desc: "garbage", text: "KIRAK SFJAN XQWZP LMNBV CXYTR EWQAZ", natural: false},
+	}
+	for _, tC := range testCases {
+		s.Run(tC.desc, func() {
+			a := Score([]byte(tC.text))
+			s.Positive(a.Quadgrams)
+			if tC.natural {
+				s.Equal(tC.language, a.Language)
+				s.Greater(a.Confidence, 0.7)
+			} else {
+				s.Less(a.Confidence, 0.2)
+			}
+		})
+	}
+}
+
+func (s *LanguageSuite) TestScoreWrongKey() {
+	sol, err := New(WithPassphrase([]byte("FOO")))
+	s.Require().NoError(err)
+	ct, err := sol.Encrypt([]byte(depthPlaintextB))
+	s.Require().NoError(err)
+
+	sol, err = New(WithPassphrase([]byte("FOB")))
+	s.Require().NoError(err)
+	pt, err := sol.Decrypt(ct)
+	s.Require().NoError(err)
+	s.Less(Score(pt).Confidence, 0.2)
+}
+
+func (s *LanguageSuite) TestScoreShortText() {
+	a := Score([]byte("ABC"))
+	s.Zero(a.Quadgrams)
+	s.Zero(a.Confidence)
+}
+
+func (s *LanguageSuite) TestLoadQuadgrams() {
+	q, err := LoadQuadgrams(strings.NewReader("ABCD 10\nbcde 30\n\n"))
+	s.Require().NoError(err)
+	score, n := q.Score([]byte("ABCDE"))
+	s.Equal(2, n)
+	s.InDelta((-0.60206-0.124939)/2, score, 1e-5)
+	s.Equal(1.0, q.Confidence(0))
+
+	RegisterLanguage("test", q)
+	defer func() {
+		languagesMu.Lock()
+		delete(languages, "test")
+		languagesMu.Unlock()
+	}()
+	s.Equal(q, Language("test"))
+	s.Equal("test", Score([]byte("ABCDE")).Language)
+}
+
+func (s *LanguageSuite) TestLoadQuadgramsInvalid() {
+	for _, input := range []string{"", "ABC 1", "ABCD", "ABCD x", "ABCD -1", "AB1D 1"} {
+		_, err := LoadQuadgrams(strings.NewReader(input))
+		s.Error(err, input)
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	suite.Run(t, new(LanguageSuite))
+}
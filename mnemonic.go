@@ -0,0 +1,93 @@
+package solitaire
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+//go:embed data/mnemonic_en.txt
+var mnemonicData string
+
+// mnemonicWords is the BIP39 English word list of 2048 words. Each word is
+// determined by its first four letters.
+var mnemonicWords = strings.Fields(mnemonicData)
+
+// mnemonicIndex maps the words of the word list and their first four
+// letters to their index.
+var mnemonicIndex = func() map[string]int {
+	m := make(map[string]int, 2*len(mnemonicWords))
+	for i, w := range mnemonicWords {
+		m[w] = i
+		if len(w) > 4 {
+			m[w[:4]] = i
+		}
+	}
+	return m
+}()
+
+const (
+	// mnemonicBits is the number of bits encoded by a word.
+	mnemonicBits = 11
+	// mnemonicRankWords is the number of words needed for the rank of a deck,
+	// as 2^(11*22) > 54!.
+	mnemonicRankWords = 22
+)
+
+// Mnemonic returns the deck as 23 words of the BIP39 English word list
+// for memorizing it: its rank in base 2048, most significant word first,
+// followed by a checksum word taken from the SHA-256 hash of the rank.
+// Use DeckFromMnemonic to get the deck back.
+func (d *Deck) Mnemonic() []string {
+	rank := d.Rank()
+	words := make([]string, mnemonicRankWords+1)
+	r, base, m := new(big.Int).Set(rank), big.NewInt(1<<mnemonicBits), new(big.Int)
+	for i := mnemonicRankWords - 1; i >= 0; i-- {
+		r.QuoRem(r, base, m)
+		words[i] = mnemonicWords[m.Int64()]
+	}
+	words[mnemonicRankWords] = mnemonicWords[mnemonicChecksum(rank)]
+	return words
+}
+
+// mnemonicChecksum returns the first eleven bits of the SHA-256 hash of the
+// rank as 32 bytes, big endian.
+func mnemonicChecksum(rank *big.Int) int {
+	var b [32]byte
+	sum := sha256.Sum256(rank.FillBytes(b[:]))
+	return int(sum[0])<<3 | int(sum[1])>>5
+}
+
+// DeckFromMnemonic returns the deck for the words produced by Mnemonic.
+// The words are case-insensitive and may be abbreviated to their first four
+// letters. If a word is unknown or the checksum word does not match, it
+// returns an error.
+func DeckFromMnemonic(words []string) (*Deck, error) {
+	if len(words) != mnemonicRankWords+1 {
+		return nil, fmt.Errorf("mnemonic must contain %d words, got %d", mnemonicRankWords+1, len(words))
+	}
+	indexes := make([]int, len(words))
+	for i, w := range words {
+		idx, ok := mnemonicIndex[strings.ToLower(w)]
+		if !ok {
+			return nil, fmt.Errorf("unknown word %q at position %d", w, i+1)
+		}
+		indexes[i] = idx
+	}
+	rank, base := new(big.Int), big.NewInt(1<<mnemonicBits)
+	for _, idx := range indexes[:mnemonicRankWords] {
+		rank.Mul(rank, base)
+		rank.Add(rank, big.NewInt(int64(idx)))
+	}
+	if expected := mnemonicChecksum(rank); indexes[mnemonicRankWords] != expected {
+		return nil, fmt.Errorf("checksum word %q does not match, expected %q: a word is wrong or misplaced",
+			words[mnemonicRankWords], mnemonicWords[expected])
+	}
+	d, err := DeckFromRank(rank)
+	if err != nil {
+		return nil, fmt.Errorf("mnemonic is out of range: %w", err)
+	}
+	return d, nil
+}
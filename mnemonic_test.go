@@ -0,0 +1,97 @@
+package solitaire
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MnemonicSuite struct {
+	suite.Suite
+}
+
+func (s *MnemonicSuite) TestWordList() {
+	s.Len(mnemonicWords, 2048)
+	// The first four letters of each word are unique.
+	s.Len(mnemonicIndex, 2048+countLonger(4))
+	s.Equal("abandon", mnemonicWords[0])
+	s.Equal("zoo", mnemonicWords[2047])
+}
+
+func countLonger(n int) int {
+	c := 0
+	for _, w := range mnemonicWords {
+		if len(w) > n {
+			c++
+		}
+	}
+	return c
+}
+
+func (s *MnemonicSuite) TestInitialDeck() {
+	d := Deck{}
+	copy(d[:], initialDeck)
+	words := d.Mnemonic()
+	s.Len(words, 23)
+	s.Equal(strings.Repeat("abandon ", 22), strings.Join(words[:22], " ")+" ")
+}
+
+func (s *MnemonicSuite) TestRoundTrip() {
+	for range 20 {
+		d, err := RandomDeck()
+		s.Require().NoError(err)
+		got, err := DeckFromMnemonic(d.Mnemonic())
+		s.Require().NoError(err)
+		s.Equal(*d, *got)
+	}
+}
+
+func (s *MnemonicSuite) TestAbbreviated() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	words := sol.deck.Mnemonic()
+	short := make([]string, len(words))
+	for i, w := range words {
+		short[i] = strings.ToUpper(w[:min(4, len(w))])
+	}
+	d, err := DeckFromMnemonic(short)
+	s.Require().NoError(err)
+	s.Equal(*sol.deck, *d)
+}
+
+func (s *MnemonicSuite) TestInvalid() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	words := sol.deck.Mnemonic()
+
+	_, err = DeckFromMnemonic(words[:22])
+	s.Error(err)
+
+	unknown := append([]string{}, words...)
+	unknown[3] = "solitaire"
+	_, err = DeckFromMnemonic(unknown)
+	s.ErrorContains(err, "position 4")
+
+	swapped := append([]string{}, words...)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	if swapped[0] != swapped[1] {
+		_, err = DeckFromMnemonic(swapped)
+		s.ErrorContains(err, "checksum")
+	}
+
+	// The largest number of 22 words is beyond 54!.
+	out := make([]string, 23)
+	for i := range out {
+		out[i] = "zoo"
+	}
+	max := new(big.Int).Lsh(big.NewInt(1), 242)
+	out[22] = mnemonicWords[mnemonicChecksum(max.Sub(max, big.NewInt(1)))]
+	_, err = DeckFromMnemonic(out)
+	s.ErrorContains(err, "out of range")
+}
+
+func TestMnemonic(t *testing.T) {
+	suite.Run(t, new(MnemonicSuite))
+}
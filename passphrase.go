@@ -0,0 +1,103 @@
+package solitaire
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// PassphraseMode is the way GeneratePassphrase builds a passphrase.
+type PassphraseMode int
+
+const (
+	// PassphraseLetters are random letters, log2(26) bits each.
+	PassphraseLetters PassphraseMode = iota
+	// PassphraseWords are random words of the BIP39 English word list,
+	// 11 bits each, in upper case and joined without spaces.
+	PassphraseWords
+	// PassphraseSyllables are random syllables of a consonant and a vowel,
+	// log2(100) bits each, which are easier to pronounce and remember.
+	PassphraseSyllables
+)
+
+var passphraseModeNames = [...]string{PassphraseLetters: "letters", PassphraseWords: "words", PassphraseSyllables: "syllables"}
+
+func (m PassphraseMode) String() string {
+	if m < PassphraseLetters || m > PassphraseSyllables {
+		panic("invalid passphrase mode")
+	}
+	return passphraseModeNames[m]
+}
+
+// ParsePassphraseMode parses the name of a mode as returned by String.
+func ParsePassphraseMode(s string) (PassphraseMode, error) {
+	for m, name := range passphraseModeNames {
+		if strings.EqualFold(s, name) {
+			return PassphraseMode(m), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown passphrase mode %q, expected one of %s", s, strings.Join(passphraseModeNames[:], ", "))
+}
+
+const (
+	syllableConsonants = "BCDFGHJKLMNPQRSTVWXZ"
+	syllableVowels     = "AEIOU"
+)
+
+// GeneratedPassphrase is the result of GeneratePassphrase.
+type GeneratedPassphrase struct {
+	// Passphrase consists of the upper-case letters A to Z only, as
+	// WithPassphrase keys any other byte as a count cut of zero.
+	Passphrase string
+	// Entropy is the entropy of the passphrase in bits, given the mode and
+	// the number of letters, words or syllables.
+	Entropy float64
+}
+
+// GeneratePassphrase returns a random passphrase of the mode with at least
+// the given entropy in bits, e.g. 128, or DeckEntropy to reach the strength
+// of a random deck. The choices are taken from crypto/rand.
+//
+// Note that keying a deck with a passphrase of more than about 237 bits does
+// not make the deck stronger.
+func GeneratePassphrase(mode PassphraseMode, bits float64) (GeneratedPassphrase, error) {
+	return generatePassphrase(mode, bits, rand.Reader)
+}
+
+func generatePassphrase(mode PassphraseMode, bits float64, r io.Reader) (GeneratedPassphrase, error) {
+	if bits <= 0 || bits > 1024 {
+		return GeneratedPassphrase{}, errors.New("entropy must be between 0 and 1024 bits")
+	}
+	var choices int
+	var choose func(int) string
+	switch mode {
+	case PassphraseLetters:
+		choices = len(alphabet)
+		choose = func(i int) string { return string(alphabet[i]) }
+	case PassphraseWords:
+		choices = len(mnemonicWords)
+		choose = func(i int) string { return strings.ToUpper(mnemonicWords[i]) }
+	case PassphraseSyllables:
+		choices = len(syllableConsonants) * len(syllableVowels)
+		choose = func(i int) string {
+			return string([]byte{syllableConsonants[i/len(syllableVowels)], syllableVowels[i%len(syllableVowels)]})
+		}
+	default:
+		return GeneratedPassphrase{}, fmt.Errorf("invalid passphrase mode %d", mode)
+	}
+
+	perChoice := math.Log2(float64(choices))
+	n := int(math.Ceil(bits / perChoice))
+	src := &readerSource{r: r}
+	var b strings.Builder
+	for range n {
+		b.WriteString(choose(uniform(src, choices)))
+	}
+	if src.err != nil {
+		return GeneratedPassphrase{}, src.err
+	}
+	return GeneratedPassphrase{Passphrase: b.String(), Entropy: float64(n) * perChoice}, nil
+}
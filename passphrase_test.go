@@ -0,0 +1,74 @@
+package solitaire
+
+import (
+	"math"
+	"math/rand/v2"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PassphraseSuite struct {
+	suite.Suite
+}
+
+func (s *PassphraseSuite) TestGenerate() {
+	for _, tc := range []struct {
+		mode    PassphraseMode
+		pattern string
+		entropy float64
+	}{
+		{PassphraseLetters, `^[A-Z]{28}$`, 28 * math.Log2(26)},
+		{PassphraseWords, `^[A-Z]{36,96}$`, 12 * 11},
+		{PassphraseSyllables, `^([BCDFGHJ-NP-TVWXZ][AEIOU]){20}$`, 20 * math.Log2(100)},
+	} {
+		p, err := GeneratePassphrase(tc.mode, 128)
+		s.Require().NoError(err, tc.mode)
+		s.Regexp(tc.pattern, p.Passphrase, tc.mode)
+		s.InDelta(tc.entropy, p.Entropy, 1e-9, tc.mode)
+		s.GreaterOrEqual(p.Entropy, 128.0)
+		s.Regexp(regexp.MustCompile(`^[A-Z]+$`), p.Passphrase, "Every letter keys the deck")
+
+		other, err := GeneratePassphrase(tc.mode, 128)
+		s.Require().NoError(err)
+		s.NotEqual(p.Passphrase, other.Passphrase)
+
+		// Different passphrases key different decks.
+		d, err := New(WithPassphrase([]byte(p.Passphrase)))
+		s.Require().NoError(err)
+		otherDeck, err := New(WithPassphrase([]byte(other.Passphrase)))
+		s.Require().NoError(err)
+		s.NotEqual(*d.deck, *otherDeck.deck, tc.mode)
+	}
+}
+
+func (s *PassphraseSuite) TestGenerateDeterministic() {
+	p, err := generatePassphrase(PassphraseLetters, DeckEntropy, rand.NewChaCha8([32]byte{}))
+	s.Require().NoError(err)
+	s.Len(p.Passphrase, 51)
+	again, err := generatePassphrase(PassphraseLetters, DeckEntropy, rand.NewChaCha8([32]byte{}))
+	s.Require().NoError(err)
+	s.Equal(p, again)
+}
+
+func (s *PassphraseSuite) TestGenerateInvalid() {
+	_, err := GeneratePassphrase(PassphraseLetters, 0)
+	s.Error(err)
+	_, err = GeneratePassphrase(PassphraseMode(7), 128)
+	s.Error(err)
+}
+
+func (s *PassphraseSuite) TestParseMode() {
+	for m := PassphraseLetters; m <= PassphraseSyllables; m++ {
+		parsed, err := ParsePassphraseMode(m.String())
+		s.Require().NoError(err)
+		s.Equal(m, parsed)
+	}
+	_, err := ParsePassphraseMode("emoji")
+	s.Error(err)
+}
+
+func TestPassphrase(t *testing.T) {
+	suite.Run(t, new(PassphraseSuite))
+}
@@ -0,0 +1,47 @@
+package solitaire
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+)
+
+// DeckEntropy is the entropy of a uniformly random deck in bits, log2(54!).
+// A passphrase of random letters needs about 51 letters to reach it.
+const DeckEntropy = 237.06381108042942967
+
+// readerSource is a math/rand/v2.Source reading from r. The first error
+// from r is kept in err and all further values are zero.
+type readerSource struct {
+	r   io.Reader
+	err error
+}
+
+func (src *readerSource) Uint64() uint64 {
+	var b [8]byte
+	if src.err == nil {
+		_, src.err = io.ReadFull(src.r, b[:])
+	}
+	if src.err != nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// RandomDeck returns a uniformly random deck, shuffled with the
+// Fisher-Yates shuffle of Shuffle from crypto/rand. It carries the full
+// entropy of DeckEntropy, unlike a deck keyed from a passphrase.
+func RandomDeck() (*Deck, error) {
+	return randomDeck(rand.Reader)
+}
+
+func randomDeck(r io.Reader) (*Deck, error) {
+	d := &Deck{}
+	copy(d[:], initialDeck)
+	src := &readerSource{r: r}
+	Shuffle(src, len(d), func(i, j int) { d[i], d[j] = d[j], d[i] })
+	if src.err != nil {
+		return nil, src.err
+	}
+	return d, nil
+}
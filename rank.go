@@ -0,0 +1,128 @@
+package solitaire
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/awnumar/memguard"
+)
+
+const (
+	// letterKeyDigits is the number of letters needed for the rank of a
+	// deck in base 26, as 26^51 > 54!.
+	letterKeyDigits = 51
+	// letterKeyCheck is the number of check letters appended to a letter key,
+	// so that a letter key has eleven groups of five letters.
+	letterKeyCheck = 4
+)
+
+// deckOrderings is the number of orderings of a deck, 54!.
+var deckOrderings = new(big.Int).MulRange(1, int64(len(Deck{})))
+
+// Rank returns the number of the ordering of the deck among all 54!
+// orderings, from 0 for the initial deck to 54!-1 for the reversed one.
+// It is computed from the Lehmer code of the deck: for each position, the
+// number of cards after it that come earlier in the initial deck.
+// The deck must be valid.
+func (d *Deck) Rank() *big.Int {
+	rank := new(big.Int)
+	n := big.NewInt(0)
+	for i, c := range d {
+		smaller := 0
+		for _, o := range d[i+1:] {
+			if cardIndex(o) < cardIndex(c) {
+				smaller++
+			}
+		}
+		// rank = rank * (number of remaining cards) + smaller
+		n.SetInt64(int64(len(d) - i))
+		rank.Mul(rank, n)
+		rank.Add(rank, n.SetInt64(int64(smaller)))
+	}
+	return rank
+}
+
+// DeckFromRank returns the deck with the given rank, see Deck.Rank.
+// If the rank is negative or not smaller than 54!, it returns an error.
+func DeckFromRank(rank *big.Int) (*Deck, error) {
+	if rank == nil || rank.Sign() < 0 || rank.Cmp(deckOrderings) >= 0 {
+		return nil, fmt.Errorf("rank must be between 0 and 54!-1")
+	}
+	// Decode the Lehmer code from the last position to the first.
+	var code [len(Deck{})]int
+	r, n, m := new(big.Int).Set(rank), new(big.Int), new(big.Int)
+	for i := len(code) - 1; i >= 0; i-- {
+		n.SetInt64(int64(len(code) - i))
+		r.QuoRem(r, n, m)
+		code[i] = int(m.Int64())
+	}
+	remaining := append([]Card{}, initialDeck...)
+	d := &Deck{}
+	for i, k := range code {
+		d[i] = remaining[k]
+		remaining = append(remaining[:k], remaining[k+1:]...)
+	}
+	return d, nil
+}
+
+// LetterKey returns the deck as a key of 55 letters A to Z: its rank in
+// base 26, most significant letter first, followed by four check letters.
+// Unlike Export, it can be written down and transmitted like a passphrase.
+// Use ParseLetterKey to get the deck back.
+func (d *Deck) LetterKey() string {
+	digits := make([]byte, letterKeyDigits)
+	r, base, m := d.Rank(), big.NewInt(26), new(big.Int)
+	for i := len(digits) - 1; i >= 0; i-- {
+		r.QuoRem(r, base, m)
+		digits[i] = 'A' + byte(m.Int64())
+	}
+	return string(digits) + letterKeyChecksum(digits)
+}
+
+// letterKeyChecksum returns the check letters for the digits of a letter key,
+// taken from their SHA-256 hash.
+func letterKeyChecksum(digits []byte) string {
+	sum := sha256.Sum256(digits)
+	check := make([]byte, letterKeyCheck)
+	for i := range check {
+		check[i] = 'A' + sum[i]%26
+	}
+	return string(check)
+}
+
+// IsLetterKey reports whether the key has the shape of a key produced by
+// LetterKey: 55 letters, ignoring non-letters and case, whose check letters
+// match. A letter key used as a passphrase keys a different deck, so this
+// helps to catch the mistake.
+func IsLetterKey(key []byte) bool {
+	letters := normalizeCleartext(key)
+	defer memguard.WipeBytes(letters)
+	return len(letters) == letterKeyDigits+letterKeyCheck &&
+		letterKeyChecksum(letters[:letterKeyDigits]) == string(letters[letterKeyDigits:])
+}
+
+// ParseLetterKey parses a key produced by LetterKey. Non-letters like the
+// spaces between groups of five are ignored and the letters are
+// case-insensitive. If the check letters do not match, e.g. because of a
+// typo, it returns an error.
+func ParseLetterKey(key string) (*Deck, error) {
+	letters := normalizeCleartext([]byte(key))
+	if len(letters) != letterKeyDigits+letterKeyCheck {
+		return nil, fmt.Errorf("letter key must contain %d letters, got %d", letterKeyDigits+letterKeyCheck, len(letters))
+	}
+	digits, check := letters[:letterKeyDigits], string(letters[letterKeyDigits:])
+	if expected := letterKeyChecksum(digits); check != expected {
+		return nil, fmt.Errorf("check letters %s do not match, expected %s: the key contains a typo", check, expected)
+	}
+	r, base := new(big.Int), big.NewInt(26)
+	for _, c := range digits {
+		r.Mul(r, base)
+		r.Add(r, big.NewInt(int64(c-'A')))
+	}
+	d, err := DeckFromRank(r)
+	if err != nil {
+		return nil, fmt.Errorf("letter key is out of range: %w", err)
+	}
+	return d, nil
+}
@@ -0,0 +1,100 @@
+package solitaire
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RankSuite struct {
+	suite.Suite
+}
+
+func (s *RankSuite) TestRankInitialDeck() {
+	d := Deck{}
+	copy(d[:], initialDeck)
+	s.Equal(int64(0), d.Rank().Int64())
+
+	for i, j := 0, len(d)-1; i < j; i, j = i+1, j-1 {
+		d[i], d[j] = d[j], d[i]
+	}
+	s.Equal(new(big.Int).Sub(deckOrderings, big.NewInt(1)), d.Rank(), "The reversed deck has the highest rank")
+}
+
+func (s *RankSuite) TestRankRoundTrip() {
+	for range 20 {
+		d, err := RandomDeck()
+		s.Require().NoError(err)
+		got, err := DeckFromRank(d.Rank())
+		s.Require().NoError(err)
+		s.Equal(*d, *got)
+	}
+	d, err := DeckFromRank(big.NewInt(1))
+	s.Require().NoError(err)
+	s.Equal("C1,C2", strings.Join(strings.Split(d.Export(), ",")[:2], ","))
+	s.True(strings.HasSuffix(d.Export(), ",JB,JA"), "Rank 1 swaps the last two cards")
+}
+
+func (s *RankSuite) TestDeckFromRankInvalid() {
+	for _, r := range []*big.Int{nil, big.NewInt(-1), deckOrderings} {
+		_, err := DeckFromRank(r)
+		s.Error(err)
+	}
+}
+
+func (s *RankSuite) TestLetterKey() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	key := sol.deck.LetterKey()
+	s.Len(key, 55)
+	s.Regexp(`^[A-Z]+$`, key)
+
+	d, err := ParseLetterKey(strings.ToLower(string(BlocksOfFive([]byte(key)))))
+	s.Require().NoError(err)
+	s.Equal(*sol.deck, *d)
+
+	initial := Deck{}
+	copy(initial[:], initialDeck)
+	s.True(strings.HasPrefix(initial.LetterKey(), strings.Repeat("A", 51)))
+}
+
+func (s *RankSuite) TestIsLetterKey() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	key := sol.deck.LetterKey()
+	s.True(IsLetterKey([]byte(key)))
+	s.True(IsLetterKey(bytes.ToLower(BlocksOfFive([]byte(key)))))
+
+	typo := []byte(key)
+	typo[10] = 'A' + (typo[10]-'A'+1)%26
+	s.False(IsLetterKey(typo))
+	s.False(IsLetterKey([]byte(key[:54])))
+	s.False(IsLetterKey([]byte("CRYPTONOMICON")))
+}
+
+func (s *RankSuite) TestParseLetterKeyInvalid() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	key := []byte(sol.deck.LetterKey())
+	typo := append([]byte{}, key...)
+	typo[10] = 'A' + (typo[10]-'A'+1)%26
+	swapped := append([]byte{}, key...)
+	swapped[3], swapped[4] = swapped[4], swapped[3]
+	out := []byte(strings.Repeat("Z", 51))
+	out = append(out, letterKeyChecksum(out)...)
+
+	for _, k := range [][]byte{typo, swapped, key[:50], out} {
+		if string(k) == string(key) {
+			continue
+		}
+		_, err := ParseLetterKey(string(k))
+		s.Error(err, string(k))
+	}
+}
+
+func TestRank(t *testing.T) {
+	suite.Run(t, new(RankSuite))
+}
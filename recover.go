@@ -0,0 +1,315 @@
+package solitaire
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// PartialDeck is a deck in which some cards may be unknown.
+// Unknown cards are represented by the zero Card.
+type PartialDeck [54]Card
+
+// Unknown returns the number of unknown cards in the deck.
+func (p *PartialDeck) Unknown() int {
+	n := 0
+	for _, c := range p {
+		if c == (Card{}) {
+			n++
+		}
+	}
+	return n
+}
+
+// String returns the deck in the format of Deck.Export,
+// with "??" for unknown cards.
+func (p *PartialDeck) String() string {
+	codes := make([]string, len(p))
+	for i, c := range p {
+		if c == (Card{}) {
+			codes[i] = "??"
+			continue
+		}
+		codes[i] = c.Code()
+	}
+	return strings.Join(codes, ",")
+}
+
+// ParsePartialDeck parses a deck in the format of PartialDeck.String.
+// Known cards must not occur more than once.
+func ParsePartialDeck(s string) (*PartialDeck, error) {
+	codes := strings.Split(s, ",")
+	if len(codes) != len(PartialDeck{}) {
+		return nil, fmt.Errorf("deck must contain %d cards, got %d", len(PartialDeck{}), len(codes))
+	}
+	p := &PartialDeck{}
+	for i, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "??" || code == "?" {
+			continue
+		}
+		c, err := parseCardCode(code)
+		if err != nil {
+			return nil, fmt.Errorf("position %d: %w", i+1, err)
+		}
+		p[i] = c
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// validate checks that the known cards are valid and unique.
+func (p *PartialDeck) validate() error {
+	seen := make(map[Card]bool, len(p))
+	for i, c := range p {
+		if c == (Card{}) {
+			continue
+		}
+		if !isValidCard(c) {
+			return fmt.Errorf("invalid card at position %d", i+1)
+		}
+		if seen[c] {
+			return fmt.Errorf("duplicate card %s at position %d", c, i+1)
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+// KeystreamFromPair derives the keystream shifts from a known plaintext and
+// the corresponding ciphertext. Each shift is the keystream value modulo 26,
+// which is all that can be learned from a plaintext and ciphertext letter.
+// Both texts are normalized like a cleartext, without padding.
+func KeystreamFromPair(plaintext, ciphertext []byte) ([]int, error) {
+	p := normalizeCleartext(plaintext)
+	c := normalizeCleartext(ciphertext)
+	if len(p) == 0 {
+		return nil, errors.New("plaintext must contain letters")
+	}
+	if len(p) > len(c) {
+		return nil, fmt.Errorf("plaintext of %d letters is longer than the ciphertext of %d letters", len(p), len(c))
+	}
+	shifts := make([]int, len(p))
+	for i := range p {
+		shifts[i] = (int(c[i]) - int(p[i]) + 26) % 26
+	}
+	return shifts, nil
+}
+
+// RecoverConfig configures RecoverDeck.
+type RecoverConfig struct {
+	// Known holds the cards of the deck known in advance, if any.
+	Known PartialDeck
+	// MemoryLimit is the maximum number of bytes used to store candidates.
+	// When it is reached, the search stops. Zero means no limit.
+	MemoryLimit int
+}
+
+// RecoverResult holds the result of RecoverDeck.
+type RecoverResult struct {
+	// Candidates are the deck states that produce the keystream.
+	// Cards that do not influence the keystream remain unknown.
+	Candidates []PartialDeck
+	// Nodes is the number of search nodes visited.
+	Nodes int64
+	// Complete reports whether the whole search space was explored.
+	// If it is false, there may be more candidates.
+	Complete bool
+}
+
+// errMemoryLimit stops the search when the candidates exceed the memory limit.
+var errMemoryLimit = errors.New("memory limit reached")
+
+// RecoverDeck searches for the deck states that produce the keystream, given as
+// shifts like returned by KeystreamFromPair. The deck state is the one before
+// the deck is advanced for the first keystream value.
+//
+// The search simulates the deck operations on a deck of unknown cards and
+// backtracks over the cards whenever the value of an unknown card is needed:
+// first the positions of the jokers, then the bottom card for the count cut,
+// the top card and the output card. Each output card is constrained to the two
+// cards matching the keystream shift, which prunes most branches. The search
+// is exponential in the number of unknown cards and is meant for experiments,
+// so it is bounded by the context and the memory limit.
+//
+// If the search stops early, RecoverDeck returns the candidates found so far
+// together with the reason: the error of the context or a memory limit error.
+func RecoverDeck(ctx context.Context, keystream []int, cfg RecoverConfig) (*RecoverResult, error) {
+	for i, k := range keystream {
+		if k < 0 || k > 25 {
+			return nil, fmt.Errorf("keystream shift at position %d out of range: %d", i+1, k)
+		}
+	}
+	if err := cfg.Known.validate(); err != nil {
+		return nil, fmt.Errorf("invalid known deck: %w", err)
+	}
+
+	r := &recoverer{
+		ctx:       ctx,
+		keystream: keystream,
+		result:    &RecoverResult{Candidates: make([]PartialDeck, 0)},
+	}
+	if cfg.MemoryLimit > 0 {
+		r.maxCandidates = max(1, cfg.MemoryLimit/int(unsafe.Sizeof(PartialDeck{})))
+	}
+
+	root := recoverState{}
+	for i, c := range cfg.Known {
+		root.deck[i] = placeholder(i)
+		if c != (Card{}) {
+			root.assign(i, c)
+		}
+	}
+	knownA := cfg.Known.find(Card{rank: jokerA})
+	knownB := cfg.Known.find(Card{rank: jokerB})
+	for a := range root.deck {
+		if (knownA >= 0 && a != knownA) || (knownA < 0 && root.cards[a] != (Card{})) {
+			continue
+		}
+		for b := range root.deck {
+			if a == b || (knownB >= 0 && b != knownB) || (knownB < 0 && root.cards[b] != (Card{})) {
+				continue
+			}
+			st := root
+			st.assign(a, Card{rank: jokerA})
+			st.assign(b, Card{rank: jokerB})
+			if !r.search(st, 0) {
+				return r.result, r.err
+			}
+		}
+	}
+	r.result.Complete = true
+	return r.result, nil
+}
+
+func (p *PartialDeck) find(card Card) int {
+	for i, c := range p {
+		if c == card {
+			return i
+		}
+	}
+	return -1
+}
+
+// placeholderBase is added to the original position of a card to build a
+// placeholder for an unknown card. It is larger than any suit, so placeholders
+// never equal real cards.
+const placeholderBase = 1000
+
+// placeholder returns the card that stands for the card at the original position pos.
+func placeholder(pos int) Card {
+	return Card{suit: suit(placeholderBase + pos)}
+}
+
+// recoverState is a node in the search for a deck state.
+type recoverState struct {
+	// deck holds the current order of the cards. The jokers are the real
+	// jokers, all other cards are placeholders for their original position.
+	deck Deck
+	// cards holds the card assigned to each original position, or the zero
+	// Card if it is not known yet.
+	cards PartialDeck
+	// used marks the assigned cards, indexed by their value minus one.
+	// The jokers use the indices 52 and 53.
+	used [54]bool
+}
+
+func cardIndex(c Card) int {
+	switch {
+	case c.IsJokerA():
+		return 52
+	case c.IsJokerB():
+		return 53
+	}
+	return c.Value() - 1
+}
+
+func cardFromValue(v int) Card {
+	return Card{suit: suit((v - 1) / 13 * 13), rank: rank((v-1)%13 + 1)}
+}
+
+func (st *recoverState) assign(pos int, c Card) {
+	st.cards[pos] = c
+	st.used[cardIndex(c)] = true
+	if c.IsJokerA() || c.IsJokerB() {
+		st.deck[pos] = c
+	}
+}
+
+type recoverer struct {
+	ctx           context.Context
+	keystream     []int
+	maxCandidates int
+	result        *RecoverResult
+	err           error
+}
+
+// search advances the deck and matches the output against the keystream from
+// position k on. It returns false if the search has to stop.
+func (r *recoverer) search(st recoverState, k int) bool {
+	r.result.Nodes++
+	if r.result.Nodes%4096 == 0 {
+		if err := r.ctx.Err(); err != nil {
+			r.err = err
+			return false
+		}
+	}
+	if k == len(r.keystream) {
+		if r.maxCandidates > 0 && len(r.result.Candidates) >= r.maxCandidates {
+			r.err = errMemoryLimit
+			return false
+		}
+		r.result.Candidates = append(r.result.Candidates, st.cards)
+		return true
+	}
+
+	st.deck.Move(st.deck.FindJokerA(), 1)
+	st.deck.Move(st.deck.FindJokerB(), 2)
+	st.deck.TripleCut()
+	return r.resolve(st, st.deck[len(st.deck)-1], nil, func(st recoverState, bottom int) bool {
+		st.deck.countCut(bottom % len(st.deck))
+		return r.resolve(st, st.deck[0], nil, func(st recoverState, top int) bool {
+			out := st.deck[top]
+			if out.IsJokerA() || out.IsJokerB() {
+				// Jokers produce no keystream value.
+				return r.search(st, k)
+			}
+			matches := func(v int) bool { return v%26 == r.keystream[k] }
+			return r.resolve(st, out, matches, func(st recoverState, _ int) bool {
+				return r.search(st, k+1)
+			})
+		})
+	})
+}
+
+// resolve determines the value of card c in the deck, branching over all
+// unused cards allowed by ok if c is a placeholder for an unknown card,
+// and calls next for each possibility. It returns false if the search has to stop.
+func (r *recoverer) resolve(st recoverState, c Card, ok func(int) bool, next func(recoverState, int) bool) bool {
+	if c.IsJokerA() || c.IsJokerB() {
+		return next(st, c.Value())
+	}
+	pos := int(c.suit) - placeholderBase
+	if known := st.cards[pos]; known != (Card{}) {
+		v := known.Value()
+		if ok != nil && !ok(v) {
+			return true
+		}
+		return next(st, v)
+	}
+	for v := 1; v <= 52; v++ {
+		if st.used[v-1] || (ok != nil && !ok(v)) {
+			continue
+		}
+		branch := st
+		branch.assign(pos, cardFromValue(v))
+		if !next(branch, v) {
+			return false
+		}
+	}
+	return true
+}
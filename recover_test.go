@@ -0,0 +1,111 @@
+package solitaire
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RecoverSuite struct {
+	suite.Suite
+}
+
+func (s *RecoverSuite) TestKeystreamFromPair() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	d := *sol.deck
+	ct, err := sol.Encrypt([]byte("SOLITAIRE"))
+	s.Require().NoError(err)
+
+	shifts, err := KeystreamFromPair([]byte("SOLITAIRE"), ct)
+	s.Require().NoError(err)
+	s.Len(shifts, 9)
+	keys := (&solitaire{deck: &d}).generateKeyStream(9)
+	for i, k := range keys {
+		s.Equal(k%26, shifts[i])
+	}
+
+	_, err = KeystreamFromPair([]byte("SOLITAIRES AND MORE"), ct)
+	s.Error(err)
+	_, err = KeystreamFromPair([]byte("123"), ct)
+	s.Error(err)
+}
+
+func (s *RecoverSuite) TestParsePartialDeck() {
+	d := Deck{}
+	copy(d[:], initialDeck)
+	p := PartialDeck(d)
+	p[3] = Card{}
+	p[53] = Card{}
+	parsed, err := ParsePartialDeck(p.String())
+	s.Require().NoError(err)
+	s.Equal(p, *parsed)
+	s.Equal(2, parsed.Unknown())
+
+	_, err = ParsePartialDeck("??,C1")
+	s.Error(err)
+}
+
+func (s *RecoverSuite) TestRecoverDeck() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	d := *sol.deck
+	copied := d
+	keys := (&solitaire{deck: &copied}).generateKeyStream(40)
+	shifts := make([]int, len(keys))
+	for i, k := range keys {
+		shifts[i] = k % 26
+	}
+
+	known := PartialDeck(d)
+	for _, i := range []int{0, 5, 11, 17, 23, 29, 41, 47} {
+		known[i] = Card{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	result, err := RecoverDeck(ctx, shifts, RecoverConfig{Known: known})
+	s.Require().NoError(err)
+	s.True(result.Complete)
+	s.Require().NotEmpty(result.Candidates)
+	s.Positive(result.Nodes)
+
+	found := false
+	for _, c := range result.Candidates {
+		consistent := true
+		for i, card := range c {
+			if card != (Card{}) && card != d[i] {
+				consistent = false
+			}
+		}
+		found = found || consistent
+	}
+	s.True(found, "Expected the original deck among the candidates")
+}
+
+func (s *RecoverSuite) TestRecoverDeckMemoryLimit() {
+	shifts := []int{1, 2, 3}
+	result, err := RecoverDeck(context.Background(), shifts, RecoverConfig{MemoryLimit: 1})
+	s.ErrorIs(err, errMemoryLimit)
+	s.False(result.Complete)
+	s.Len(result.Candidates, 1)
+}
+
+func (s *RecoverSuite) TestRecoverDeckTimeout() {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	result, err := RecoverDeck(ctx, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, RecoverConfig{})
+	s.ErrorIs(err, context.DeadlineExceeded)
+	s.False(result.Complete)
+}
+
+func (s *RecoverSuite) TestRecoverDeckInvalidKeystream() {
+	_, err := RecoverDeck(context.Background(), []int{26}, RecoverConfig{})
+	s.Error(err)
+}
+
+func TestRecover(t *testing.T) {
+	suite.Run(t, new(RecoverSuite))
+}
@@ -0,0 +1,268 @@
+package solitaire
+
+import (
+	"errors"
+	"math"
+)
+
+// TransmissionErrorKind tells whether a letter was lost or added in transmission.
+type TransmissionErrorKind int
+
+const (
+	// LetterDropped means that a letter of the ciphertext was lost.
+	LetterDropped TransmissionErrorKind = iota
+	// LetterInserted means that an extra letter was added to the ciphertext,
+	// e.g. by writing a letter twice.
+	LetterInserted
+)
+
+func (k TransmissionErrorKind) String() string {
+	switch k {
+	case LetterDropped:
+		return "dropped"
+	case LetterInserted:
+		return "inserted"
+	default:
+		panic("invalid transmission error kind")
+	}
+}
+
+// TransmissionError is a transmission error assumed by Repair.
+type TransmissionError struct {
+	Kind TransmissionErrorKind
+	// Position is the index of the received ciphertext letter, ignoring
+	// non-letters and a fingerprint header, at which the error was assumed. For a dropped letter,
+	// it is the index of the letter following the lost one. For an inserted
+	// letter, it is the index of the extra letter.
+	Position int
+}
+
+// RepairResult is the result of Repair.
+type RepairResult struct {
+	// Plaintext is the repaired plaintext in blocks of five.
+	// Letters lost in transmission are shown as '?'.
+	Plaintext []byte
+	// Errors are the assumed transmission errors, in the order of their positions.
+	Errors []TransmissionError
+	// Repaired reports whether the whole plaintext looks like natural language
+	// after the repair. If it is false, the plaintext contains garbage from the
+	// first position that could not be repaired on.
+	Repaired bool
+	// Assessment is the language model assessment of the repaired plaintext.
+	Assessment Assessment
+}
+
+// ErrNotLanguage is returned by Repair if the plaintext does not look like
+// natural language from the start, which usually means that the key is wrong.
+var ErrNotLanguage = errors.New("plaintext does not look like natural language")
+
+const (
+	// repairWindow is the number of quadgrams over which the plaintext is
+	// scored to detect garbage and to compare repairs.
+	repairWindow = 32
+	// repairMargin is the number of quadgrams before a garbage window that are
+	// included when comparing repairs.
+	repairMargin = 8
+	// repairThreshold is the confidence below which a plaintext window is
+	// considered garbage. Windows of natural text rarely fall below 0.5,
+	// windows of garbage rarely exceed 0.2.
+	repairThreshold = 0.35
+)
+
+// Repair decrypts a ciphertext in which letters may have been dropped or
+// inserted in transmission. Decrypt uses the keystream strictly by position,
+// so everything after such an error decrypts to garbage.
+//
+// Repair locates the first position at which the plaintext stops looking like
+// natural language, tries dropped and inserted letters around it, and keeps
+// the change that re-aligns the keystream best. It repeats this for up to
+// maxErrors errors. The language is determined from the beginning of the
+// plaintext using the registered language models.
+//
+// The assumed positions may be off by a few letters, because letters garbled
+// next to the error can look as plausible as the true ones. Errors in
+// the last few letters of the ciphertext go undetected.
+//
+// Unlike Decrypt, Repair accepts ciphertexts of any length.
+// It cannot be combined with a transposition or a codebook.
+func (s *solitaire) Repair(ciphertext []byte, maxErrors int) (*RepairResult, error) {
+	if maxErrors < 0 {
+		return nil, errors.New("maximum number of errors must not be negative")
+	}
+	if s.transpositions != nil {
+		return nil, errors.New("ciphertext with transposition cannot be repaired")
+	}
+	if s.codebook != nil {
+		// A code with a dropped letter, marked ?, cannot be expanded.
+		return nil, errors.New("ciphertext with codebook cannot be repaired")
+	}
+	received, err := s.checkHeader(normalizeCleartext(ciphertext))
+	if err != nil {
+		return nil, err
+	}
+	if len(received) == 0 {
+		return nil, errors.New("ciphertext must contain letters")
+	}
+	keys := s.generateKeyStream(len(received) + maxErrors)
+
+	// aligned[i] is the index of the received letter decrypted with the
+	// keystream value i, or -1 for a letter lost in transmission.
+	aligned := make([]int, len(received))
+	for i := range aligned {
+		aligned[i] = i
+	}
+	decrypt := func(aligned []int) []byte {
+		pt := make([]byte, len(aligned))
+		for i, r := range aligned {
+			if r < 0 {
+				pt[i] = '?'
+				continue
+			}
+			pt[i] = s.alphabet.decrypt(received[r], keys[i])
+		}
+		return pt
+	}
+
+	pt := decrypt(aligned)
+	first := Score(pt[:min(len(pt), repairWindow+3)])
+	if first.Confidence < repairThreshold {
+		return nil, ErrNotLanguage
+	}
+	model := Language(first.Language)
+	threshold := model.random + repairThreshold*(model.typical-model.random)
+
+	result := &RepairResult{Errors: make([]TransmissionError, 0)}
+	for {
+		scores := model.positionScores(pt)
+		bad := firstGarbage(scores, threshold)
+		if bad < 0 {
+			result.Repaired = true
+			break
+		}
+		if len(result.Errors) == maxErrors {
+			break
+		}
+
+		// The window starting at bad contains mostly garbage,
+		// so the error is within the window.
+		bestScore := windowScore(scores, max(0, bad-repairMargin), repairWindow+repairMargin)
+		var best []int
+		var bestError TransmissionError
+		for e := max(0, bad-repairMargin); e < min(len(aligned), bad+repairWindow); e++ {
+			if aligned[e] < 0 {
+				continue
+			}
+			candidates := []struct {
+				aligned []int
+				err     TransmissionError
+			}{
+				{
+					aligned: append(append(append([]int{}, aligned[:e]...), -1), aligned[e:]...),
+					err:     TransmissionError{Kind: LetterDropped, Position: aligned[e]},
+				},
+				{
+					aligned: append(append([]int{}, aligned[:e]...), aligned[e+1:]...),
+					err:     TransmissionError{Kind: LetterInserted, Position: aligned[e]},
+				},
+			}
+			for _, c := range candidates {
+				if len(c.aligned) > len(keys) {
+					continue
+				}
+				score := windowScore(model.positionScores(decrypt(c.aligned)), max(0, bad-repairMargin), repairWindow+repairMargin)
+				if score > bestScore {
+					bestScore, best, bestError = score, c.aligned, c.err
+				}
+			}
+		}
+		if best == nil || bestScore < threshold {
+			break
+		}
+		aligned = best
+		pt = decrypt(aligned)
+		result.Errors = append(result.Errors, bestError)
+	}
+
+	result.Plaintext = BlocksOfFive(pt)
+	result.Assessment = Score(pt)
+	return result, nil
+}
+
+// positionScores returns the log-probability of the quadgram starting at each
+// position of the text. A '?' for a dropped letter is scored as the most likely
+// letter in its place. Quadgrams containing other characters than A to Z get
+// NaN and are not scored.
+func (q *Quadgrams) positionScores(text []byte) []float64 {
+	if len(text) < 4 {
+		return nil
+	}
+	text = q.fillGaps(text)
+	scores := make([]float64, len(text)-3)
+	for i := range scores {
+		quad := text[i : i+4]
+		if len(normalizeCleartext(quad)) != 4 {
+			scores[i] = math.NaN()
+			continue
+		}
+		scores[i] = float64(q.logProb[quadgramIndex(quad)])
+	}
+	return scores
+}
+
+// fillGaps returns a copy of the text in which each '?' is replaced by the
+// letter that maximizes the probability of the quadgrams containing it.
+func (q *Quadgrams) fillGaps(text []byte) []byte {
+	filled := text
+	for i, c := range text {
+		if c != '?' {
+			continue
+		}
+		if &filled[0] == &text[0] {
+			filled = append([]byte{}, text...)
+		}
+		best, bestScore := byte('A'), math.Inf(-1)
+		for _, l := range alphabet {
+			filled[i] = l
+			score := 0.0
+			for j := max(0, i-3); j <= i && j+4 <= len(filled); j++ {
+				if quad := filled[j : j+4]; len(normalizeCleartext(quad)) == 4 {
+					score += float64(q.logProb[quadgramIndex(quad)])
+				}
+			}
+			if score > bestScore {
+				best, bestScore = l, score
+			}
+		}
+		filled[i] = best
+	}
+	return filled
+}
+
+// windowScore returns the mean of up to n scores starting at start,
+// skipping NaN scores. It returns -Inf if there is no score.
+func windowScore(scores []float64, start, n int) float64 {
+	sum, count := 0.0, 0
+	for _, s := range scores[min(start, len(scores)):min(start+n, len(scores))] {
+		if !math.IsNaN(s) {
+			sum += s
+			count++
+		}
+	}
+	if count == 0 {
+		return math.Inf(-1)
+	}
+	return sum / float64(count)
+}
+
+// firstGarbage returns the first position from which a window of quadgram
+// scores falls below the threshold, or -1 if there is none. Only full windows
+// are considered, unless the text is shorter than a window, so that the few
+// quadgrams of the padding at the end are not mistaken for garbage.
+func firstGarbage(scores []float64, threshold float64) int {
+	for i := 0; i == 0 || i+repairWindow <= len(scores); i++ {
+		if len(scores) > 0 && windowScore(scores, i, repairWindow) < threshold {
+			return i
+		}
+	}
+	return -1
+}
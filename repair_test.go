@@ -0,0 +1,108 @@
+package solitaire
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RepairSuite struct {
+	suite.Suite
+	ciphertext []byte
+	plaintext  []byte
+}
+
+func (s *RepairSuite) SetupTest() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	ct, err := sol.Encrypt([]byte(depthPlaintextA))
+	s.Require().NoError(err)
+	s.ciphertext = normalizeCleartext(ct)
+	s.plaintext = normalizeCleartext([]byte(depthPlaintextA))
+}
+
+func (s *RepairSuite) repair(ciphertext []byte, maxErrors int) (*RepairResult, error) {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	return sol.Repair(ciphertext, maxErrors)
+}
+
+func (s *RepairSuite) TestUndamaged() {
+	r, err := s.repair(s.ciphertext, 3)
+	s.Require().NoError(err)
+	s.True(r.Repaired)
+	s.Empty(r.Errors)
+	s.Equal(s.plaintext, unblock(r.Plaintext)[:len(s.plaintext)])
+}
+
+func (s *RepairSuite) TestDroppedLetter() {
+	damaged := append(bytes.Clone(s.ciphertext[:100]), s.ciphertext[101:]...)
+	r, err := s.repair(damaged, 3)
+	s.Require().NoError(err)
+	s.True(r.Repaired)
+	s.Require().Len(r.Errors, 1)
+	s.Equal(LetterDropped, r.Errors[0].Kind)
+	// A letter garbled next to the gap can look as plausible as the true one.
+	s.InDelta(100, r.Errors[0].Position, 2)
+
+	pt := unblock(r.Plaintext)
+	s.Equal(s.plaintext[:98], pt[:98])
+	s.Equal(byte('?'), pt[r.Errors[0].Position])
+	s.Equal(s.plaintext[103:], pt[103:len(s.plaintext)])
+}
+
+func (s *RepairSuite) TestInsertedLetter() {
+	damaged := append(bytes.Clone(s.ciphertext[:201]), s.ciphertext[200:]...)
+	r, err := s.repair(damaged, 3)
+	s.Require().NoError(err)
+	s.True(r.Repaired)
+	s.Require().Len(r.Errors, 1)
+	s.Equal(LetterInserted, r.Errors[0].Kind)
+	// Either of the two equal letters can be the extra one.
+	s.Contains([]int{200, 201}, r.Errors[0].Position)
+	s.Equal(s.plaintext, unblock(r.Plaintext)[:len(s.plaintext)])
+}
+
+func (s *RepairSuite) TestSeveralErrors() {
+	damaged := append(bytes.Clone(s.ciphertext[:80]), s.ciphertext[81:300]...)
+	damaged = append(damaged, 'Q')
+	damaged = append(damaged, s.ciphertext[300:]...)
+	r, err := s.repair(damaged, 3)
+	s.Require().NoError(err)
+	s.True(r.Repaired)
+	s.Require().Len(r.Errors, 2)
+	s.Equal(LetterDropped, r.Errors[0].Kind)
+	s.InDelta(80, r.Errors[0].Position, 2)
+	s.Equal(LetterInserted, r.Errors[1].Kind)
+	s.InDelta(299, r.Errors[1].Position, 2)
+}
+
+func (s *RepairSuite) TestMaxErrors() {
+	damaged := append(bytes.Clone(s.ciphertext[:100]), s.ciphertext[101:]...)
+	r, err := s.repair(damaged, 0)
+	s.Require().NoError(err)
+	s.False(r.Repaired)
+	s.Empty(r.Errors)
+}
+
+func (s *RepairSuite) TestNegativeMaxErrors() {
+	_, err := s.repair(s.ciphertext, -1)
+	s.Error(err)
+}
+
+func (s *RepairSuite) TestWrongKey() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICOM")))
+	s.Require().NoError(err)
+	_, err = sol.Repair(s.ciphertext, 3)
+	s.ErrorIs(err, ErrNotLanguage)
+}
+
+// unblock removes the spaces and newlines inserted by BlocksOfFive.
+func unblock(b []byte) []byte {
+	return bytes.Join(bytes.Fields(b), nil)
+}
+
+func TestRepair(t *testing.T) {
+	suite.Run(t, new(RepairSuite))
+}
@@ -0,0 +1,231 @@
+package solitaire
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+//go:embed data/words_*.txt
+var wordData embed.FS
+
+// maxPadding is the largest number of padding letters Encrypt appends.
+const maxPadding = 4
+
+// Dictionary is a language model holding the log-probabilities of words,
+// used to split a run of letters into words.
+type Dictionary struct {
+	logProb map[string]float64
+	// logTotal is the log10 of the sum of all word counts.
+	logTotal float64
+	// minLogProb is the log-probability of the rarest word.
+	minLogProb float64
+	// maxLen is the length of the longest word.
+	maxLen int
+}
+
+// LoadDictionary reads a word list with one word per line, followed by its
+// count, separated by whitespace, e.g. "THE 23135851162".
+// Words are normalized like a cleartext, counts of words that are equal after
+// normalization are added up.
+func LoadDictionary(r io.Reader) (*Dictionary, error) {
+	counts := make(map[string]float64)
+	total := 0.0
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := bytes.Fields(scanner.Bytes())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected word and count", line)
+		}
+		word := normalizeCleartext(fields[0])
+		if len(word) == 0 {
+			return nil, fmt.Errorf("line %d: invalid word %q", line, fields[0])
+		}
+		count, err := strconv.ParseFloat(string(fields[1]), 64)
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("line %d: invalid count %q", line, fields[1])
+		}
+		counts[string(word)] += count
+		total += count
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(counts) == 0 {
+		return nil, fmt.Errorf("dictionary is empty")
+	}
+
+	d := &Dictionary{logProb: make(map[string]float64, len(counts)), logTotal: math.Log10(total)}
+	for w, c := range counts {
+		d.logProb[w] = math.Log10(c) - d.logTotal
+		d.minLogProb = min(d.minLogProb, d.logProb[w])
+		d.maxLen = max(d.maxLen, len(w))
+	}
+	return d, nil
+}
+
+// wordScore returns the log-probability of the letters as a word.
+// Unknown words are less likely than the rarest known word by a factor of ten
+// for each letter, so that the segmentation prefers known words, but keeps
+// names and garbled parts together instead of splitting them into short words.
+func (d *Dictionary) wordScore(letters []byte) float64 {
+	if p, ok := d.logProb[string(letters)]; ok {
+		return p
+	}
+	return d.minLogProb - float64(len(letters))
+}
+
+// Segment splits the text into the most likely sequence of words and returns
+// the words together with the log-probability of the sequence. The text is
+// normalized like a cleartext.
+//
+// The segmentation uses the Viterbi algorithm: for every position, it keeps
+// the most likely segmentation of the letters up to it. Words are scored by
+// their frequencies alone, without context, so a run like "MEETMEAT" may be
+// split into "MEET MEAT" rather than "MEET ME AT".
+func (d *Dictionary) Segment(text []byte) ([]string, float64) {
+	letters := normalizeCleartext(text)
+	if len(letters) == 0 {
+		return []string{}, 0
+	}
+	// best[i] is the log-probability of the best segmentation of letters[:i],
+	// and start[i] is the start of its last word.
+	best := make([]float64, len(letters)+1)
+	start := make([]int, len(letters)+1)
+	// Unknown words are considered up to the length of the longest known word,
+	// but at least of a length that covers garbled parts of a plaintext.
+	maxLen := max(d.maxLen, 20)
+	for i := 1; i <= len(letters); i++ {
+		best[i] = math.Inf(-1)
+		for j := max(0, i-maxLen); j < i; j++ {
+			if score := best[j] + d.wordScore(letters[j:i]); score > best[i] {
+				best[i], start[i] = score, j
+			}
+		}
+	}
+
+	words := make([]string, 0)
+	for i := len(letters); i > 0; i = start[i] {
+		words = append(words, string(letters[start[i]:i]))
+	}
+	for i, j := 0, len(words)-1; i < j; i, j = i+1, j-1 {
+		words[i], words[j] = words[j], words[i]
+	}
+	return words, best[len(letters)]
+}
+
+var (
+	dictionariesMu      sync.RWMutex
+	dictionaries        = make(map[string]*Dictionary)
+	loadBuiltinWordData sync.Once
+)
+
+// RegisterDictionary makes the dictionary available under the given name to
+// Segment and LookupDictionary. A dictionary registered under the name of an
+// existing dictionary replaces it. The dictionaries for English ("en") and
+// German ("de") are built in.
+func RegisterDictionary(name string, d *Dictionary) {
+	loadBuiltinDictionaries()
+	dictionariesMu.Lock()
+	defer dictionariesMu.Unlock()
+	dictionaries[name] = d
+}
+
+// LookupDictionary returns the dictionary registered under name, or nil.
+func LookupDictionary(name string) *Dictionary {
+	loadBuiltinDictionaries()
+	dictionariesMu.RLock()
+	defer dictionariesMu.RUnlock()
+	return dictionaries[name]
+}
+
+// Dictionaries returns the names of the registered dictionaries, sorted.
+func Dictionaries() []string {
+	loadBuiltinDictionaries()
+	dictionariesMu.RLock()
+	defer dictionariesMu.RUnlock()
+	names := make([]string, 0, len(dictionaries))
+	for name := range dictionaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func loadBuiltinDictionaries() {
+	loadBuiltinWordData.Do(func() {
+		for _, name := range []string{"en", "de"} {
+			f, err := wordData.Open("data/words_" + name + ".txt")
+			if err != nil {
+				panic(err)
+			}
+			d, err := LoadDictionary(f)
+			f.Close()
+			if err != nil {
+				// The embedded word lists are part of the package, so this is a programming error.
+				panic(fmt.Sprintf("invalid embedded word list %s: %s", name, err))
+			}
+			dictionariesMu.Lock()
+			dictionaries[name] = d
+			dictionariesMu.Unlock()
+		}
+	})
+}
+
+// Segmentation is the result of Segment.
+type Segmentation struct {
+	// Language is the name of the dictionary that gave the most likely segmentation.
+	Language string
+	// Words are the words of the text, in upper case.
+	Words []string
+	// Padding is the number of trailing X's that were dropped as padding.
+	Padding int
+}
+
+// String returns the words separated by spaces.
+func (s Segmentation) String() string {
+	var b bytes.Buffer
+	for i, w := range s.Words {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(w)
+	}
+	return b.String()
+}
+
+// Segment restores the word breaks of a decrypted plaintext, like
+// "MEETU SATTH EBRID GEXXX", using the registered dictionaries.
+// Up to four trailing X's are dropped as padding if the text is more likely
+// without them. The segmentation of the dictionary that explains the text best
+// is returned.
+func Segment(text []byte) Segmentation {
+	letters := normalizeCleartext(text)
+	trailing := 0
+	for trailing < min(maxPadding, len(letters)) && letters[len(letters)-1-trailing] == 'X' {
+		trailing++
+	}
+
+	result := Segmentation{Words: []string{}}
+	bestScore := math.Inf(-1)
+	for _, name := range Dictionaries() {
+		d := LookupDictionary(name)
+		for padding := 0; padding <= trailing; padding++ {
+			words, score := d.Segment(letters[:len(letters)-padding])
+			if score > bestScore {
+				bestScore = score
+				result = Segmentation{Language: name, Words: words, Padding: padding}
+			}
+		}
+	}
+	return result
+}
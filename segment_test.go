@@ -0,0 +1,111 @@
+package solitaire
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SegmentSuite struct {
+	suite.Suite
+}
+
+func (s *SegmentSuite) TestBuiltinDictionaries() {
+	s.Equal([]string{"de", "en"}, Dictionaries())
+	s.NotNil(LookupDictionary("en"))
+	s.NotNil(LookupDictionary("de"))
+	s.Nil(LookupDictionary("xx"))
+}
+
+func (s *SegmentSuite) TestSegment() {
+	testCases := []struct {
+		desc     string
+		text     string
+		language string
+		words    string
+		padding  int
+	}{
+		{
+			desc:     "English with padding",
+			text:     "MEETU SATTH EBRID GEXXX",
+			language: "en",
+			words:    "MEET US AT THE BRIDGE",
+			padding:  3,
+		},
+		{
+			desc:     "English without padding",
+			text:     "WHERE IS THE BOX",
+			language: "en",
+			words:    "WHERE IS THE BOX",
+		},
+		{
+			desc:     "English ending in X",
+			text:     "SENDT HEBOX",
+			language: "en",
+			words:    "SEND THE BOX",
+		},
+		{
+			desc:     "German",
+			text:     "TREFFPUNKTMORGENABENDAMBAHNHOFXX",
+			language: "de",
+			words:    "TREFFPUNKT MORGEN ABEND AM BAHNHOF",
+			padding:  2,
+		},
+		{
+			desc:     "German with Latin-1 umlauts",
+			text:     "Die Br\xfccke \xfcber den Fluss ist zerst\xf6rt",
+			language: "de",
+			words:    "DIE BRUECKE UEBER DEN FLUSS IST ZERSTOERT",
+		},
+	}
+	for _, tC := range testCases {
+		s.Run(tC.desc, func() {
+			seg := Segment([]byte(tC.text))
+			s.Equal(tC.language, seg.Language)
+			s.Equal(tC.words, seg.String())
+			s.Equal(tC.padding, seg.Padding)
+		})
+	}
+}
+
+func (s *SegmentSuite) TestSegmentDecrypted() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	ct, err := sol.Encrypt([]byte("It was the best of times, it was the worst of times"))
+	s.Require().NoError(err)
+	sol, err = New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	pt, err := sol.Decrypt(ct)
+	s.Require().NoError(err)
+
+	seg := Segment(pt)
+	s.Equal("IT WAS THE BEST OF TIMES IT WAS THE WORST OF TIMES", seg.String())
+	s.Equal(1, seg.Padding)
+}
+
+func (s *SegmentSuite) TestSegmentEmpty() {
+	seg := Segment([]byte("123"))
+	s.Empty(seg.Words)
+	s.Empty(seg.String())
+}
+
+func (s *SegmentSuite) TestLoadDictionary() {
+	d, err := LoadDictionary(strings.NewReader("SOLI 1\ntaire 1\n\nSOLITAIRE 10\n"))
+	s.Require().NoError(err)
+	words, _ := d.Segment([]byte("solitaire"))
+	s.Equal([]string{"SOLITAIRE"}, words)
+	words, _ = d.Segment([]byte("taire soli"))
+	s.Equal([]string{"TAIRE", "SOLI"}, words)
+}
+
+func (s *SegmentSuite) TestLoadDictionaryInvalid() {
+	for _, input := range []string{"", "THE", "THE -1", "THE X", "123 4", "THE 1 2"} {
+		_, err := LoadDictionary(strings.NewReader(input))
+		s.Error(err, input)
+	}
+}
+
+func TestSegment(t *testing.T) {
+	suite.Run(t, new(SegmentSuite))
+}
@@ -0,0 +1,208 @@
+package solitaire
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// shamirPrime is the prime 2^255-19 defining the field in which decks are
+// shared. It is larger than the number of decks, 54!.
+var shamirPrime = func() *big.Int {
+	p := new(big.Int).Lsh(big.NewInt(1), 255)
+	return p.Sub(p, big.NewInt(19))
+}()
+
+const (
+	// maxShares is the largest number of shares, as the index of a share is
+	// written as a single letter.
+	maxShares = 26
+	// shareDigits is the number of letters for the value of a share in base 26,
+	// as 26^55 > 2^255.
+	shareDigits = 55
+	// shareCheck is the number of check letters of a share.
+	shareCheck = 3
+	// shareLength is the number of letters of a share: index, threshold,
+	// value and check letters, twelve groups of five.
+	shareLength = 2 + shareDigits + shareCheck
+)
+
+// Share is a share of a deck created by SplitDeck.
+type Share struct {
+	// Index is the number of the share, from 1.
+	Index int
+	// Threshold is the number of shares needed to restore the deck.
+	Threshold int
+	// Value is the value of the sharing polynomial at Index.
+	Value *big.Int
+}
+
+// SplitDeck splits the deck into n shares, any k of which restore it with
+// CombineShares, while fewer reveal nothing about it.
+//
+// It uses Shamir's secret sharing over the field of the integers modulo
+// 2^255-19: the rank of the deck is the constant term of a random polynomial
+// of degree k-1, and share i is the value of the polynomial at i.
+// The coefficients are taken from crypto/rand.
+func SplitDeck(d *Deck, n, k int) ([]Share, error) {
+	return splitDeck(d, n, k, rand.Reader)
+}
+
+func splitDeck(d *Deck, n, k int, r io.Reader) ([]Share, error) {
+	if err := d.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid deck: %w", err)
+	}
+	if k < 1 || k > n || n > maxShares {
+		return nil, fmt.Errorf("threshold must be between 1 and the number of shares, at most %d, got %d of %d", maxShares, k, n)
+	}
+	coefficients := make([]*big.Int, k)
+	coefficients[0] = d.Rank()
+	for i := 1; i < k; i++ {
+		c, err := rand.Int(r, shamirPrime)
+		if err != nil {
+			return nil, err
+		}
+		coefficients[i] = c
+	}
+	shares := make([]Share, n)
+	for i := range shares {
+		x := big.NewInt(int64(i + 1))
+		// Evaluate the polynomial with Horner's method.
+		y := new(big.Int)
+		for j := k - 1; j >= 0; j-- {
+			y.Mul(y, x)
+			y.Add(y, coefficients[j])
+			y.Mod(y, shamirPrime)
+		}
+		shares[i] = Share{Index: i + 1, Threshold: k, Value: y}
+	}
+	return shares, nil
+}
+
+// CombineShares restores the deck from at least as many shares as their
+// threshold, using Lagrange interpolation at zero. All shares must come from
+// the same split. The restored deck is validated; wrong or mixed shares
+// usually give a number beyond the number of decks and are reported as an
+// error.
+func CombineShares(shares []Share) (*Deck, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+	k := shares[0].Threshold
+	seen := make(map[int]bool, len(shares))
+	for _, s := range shares {
+		if s.Threshold != k {
+			return nil, fmt.Errorf("share %d has threshold %d, share %d has threshold %d: shares from different splits", shares[0].Index, k, s.Index, s.Threshold)
+		}
+		if s.Index < 1 || s.Index > maxShares || s.Value == nil {
+			return nil, fmt.Errorf("invalid share %d", s.Index)
+		}
+		if seen[s.Index] {
+			return nil, fmt.Errorf("share %d is given more than once", s.Index)
+		}
+		seen[s.Index] = true
+	}
+	if len(shares) < k {
+		return nil, fmt.Errorf("%d shares are needed, got %d", k, len(shares))
+	}
+
+	secret := new(big.Int)
+	for i, si := range shares {
+		// The Lagrange basis polynomial of share i at zero is the product of
+		// x_j / (x_j - x_i) over all other shares j.
+		num, den := big.NewInt(1), big.NewInt(1)
+		for j, sj := range shares {
+			if i == j {
+				continue
+			}
+			num.Mul(num, big.NewInt(int64(sj.Index)))
+			den.Mul(den, big.NewInt(int64(sj.Index-si.Index)))
+		}
+		den.Mod(den, shamirPrime)
+		term := new(big.Int).Mul(si.Value, num)
+		term.Mul(term, den.ModInverse(den, shamirPrime))
+		secret.Add(secret, term)
+		secret.Mod(secret, shamirPrime)
+	}
+
+	d, err := DeckFromRank(secret)
+	if err != nil {
+		return nil, errors.New("the shares do not restore a deck: a share is wrong or the shares come from different splits")
+	}
+	if err := d.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid deck: %w", err)
+	}
+	return d, nil
+}
+
+// String returns the share as 60 letters: the index and the threshold as
+// letters, A being 1, the value in base 26 and three check letters.
+func (s Share) String() string {
+	letters := make([]byte, 2+shareDigits, shareLength)
+	letters[0] = 'A' + byte(s.Index-1)
+	letters[1] = 'A' + byte(s.Threshold-1)
+	v, base, m := new(big.Int).Set(s.Value), big.NewInt(26), new(big.Int)
+	for i := len(letters) - 1; i >= 2; i-- {
+		v.QuoRem(v, base, m)
+		letters[i] = 'A' + byte(m.Int64())
+	}
+	return string(append(letters, shareChecksum(letters)...))
+}
+
+// shareChecksum returns the check letters for the letters of a share,
+// taken from their SHA-256 hash.
+func shareChecksum(letters []byte) []byte {
+	sum := sha256.Sum256(letters)
+	check := make([]byte, shareCheck)
+	for i := range check {
+		check[i] = 'A' + sum[i]%26
+	}
+	return check
+}
+
+// ParseShare parses a share in the format of Share.String. Non-letters like
+// the spaces between groups of five are ignored and the letters are
+// case-insensitive. If the check letters do not match, e.g. because of a
+// typo, it returns an error.
+func ParseShare(s string) (Share, error) {
+	letters := normalizeCleartext([]byte(s))
+	if len(letters) != shareLength {
+		return Share{}, fmt.Errorf("share must contain %d letters, got %d", shareLength, len(letters))
+	}
+	body, check := letters[:shareLength-shareCheck], letters[shareLength-shareCheck:]
+	if expected := shareChecksum(body); string(check) != string(expected) {
+		return Share{}, fmt.Errorf("check letters %s do not match, expected %s: the share contains a typo", check, expected)
+	}
+	v, base := new(big.Int), big.NewInt(26)
+	for _, c := range body[2:] {
+		v.Mul(v, base)
+		v.Add(v, big.NewInt(int64(c-'A')))
+	}
+	if v.Cmp(shamirPrime) >= 0 {
+		return Share{}, errors.New("share value is out of range")
+	}
+	return Share{Index: int(body[0]-'A') + 1, Threshold: int(body[1]-'A') + 1, Value: v}, nil
+}
+
+// ParseShares parses shares in the format of Share.String written one after
+// another, e.g. given as a single text or with their groups of five spread
+// over several lines. The letters are cut into shares of 60 letters each,
+// which ParseShare parses.
+func ParseShares(s string) ([]Share, error) {
+	letters := normalizeCleartext([]byte(s))
+	if len(letters) == 0 || len(letters)%shareLength != 0 {
+		return nil, fmt.Errorf("shares must have %d letters each, got %d letters in total", shareLength, len(letters))
+	}
+	shares := make([]Share, 0, len(letters)/shareLength)
+	for i := 0; i < len(letters); i += shareLength {
+		share, err := ParseShare(string(letters[i : i+shareLength]))
+		if err != nil {
+			return nil, fmt.Errorf("share %d: %w", len(shares)+1, err)
+		}
+		shares = append(shares, share)
+	}
+	return shares, nil
+}
@@ -0,0 +1,122 @@
+package solitaire
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ShamirSuite struct {
+	suite.Suite
+	deck *Deck
+}
+
+func (s *ShamirSuite) SetupTest() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	s.deck = sol.deck
+}
+
+func (s *ShamirSuite) TestSplitAndCombine() {
+	shares, err := SplitDeck(s.deck, 5, 3)
+	s.Require().NoError(err)
+	s.Len(shares, 5)
+
+	// Any three shares restore the deck.
+	for a := 0; a < 5; a++ {
+		for b := a + 1; b < 5; b++ {
+			for c := b + 1; c < 5; c++ {
+				d, err := CombineShares([]Share{shares[c], shares[a], shares[b]})
+				s.Require().NoError(err)
+				s.Equal(*s.deck, *d)
+			}
+		}
+	}
+	d, err := CombineShares(shares)
+	s.Require().NoError(err)
+	s.Equal(*s.deck, *d, "More shares than the threshold are fine")
+
+	_, err = CombineShares(shares[:2])
+	s.ErrorContains(err, "3 shares are needed")
+}
+
+func (s *ShamirSuite) TestCombineInvalid() {
+	shares, err := SplitDeck(s.deck, 3, 2)
+	s.Require().NoError(err)
+	other, err := SplitDeck(s.deck, 3, 2)
+	s.Require().NoError(err)
+
+	_, err = CombineShares(nil)
+	s.Error(err)
+	_, err = CombineShares([]Share{shares[0], shares[0]})
+	s.ErrorContains(err, "more than once")
+	_, err = CombineShares([]Share{shares[0], other[1]})
+	s.ErrorContains(err, "do not restore a deck")
+
+	three, err := SplitDeck(s.deck, 3, 3)
+	s.Require().NoError(err)
+	_, err = CombineShares([]Share{shares[0], three[1]})
+	s.ErrorContains(err, "different splits")
+}
+
+func (s *ShamirSuite) TestSplitInvalid() {
+	for _, tC := range []struct{ n, k int }{{3, 0}, {3, 4}, {27, 2}} {
+		_, err := SplitDeck(s.deck, tC.n, tC.k)
+		s.Error(err, "%d of %d", tC.k, tC.n)
+	}
+	_, err := SplitDeck(&Deck{}, 3, 2)
+	s.Error(err)
+	_, err = splitDeck(s.deck, 3, 2, bytes.NewReader(nil))
+	s.Error(err)
+}
+
+func (s *ShamirSuite) TestShareString() {
+	shares, err := SplitDeck(s.deck, 26, 2)
+	s.Require().NoError(err)
+	for _, share := range shares {
+		str := share.String()
+		s.Len(str, 60)
+		s.Regexp(`^[A-Z]+$`, str)
+		parsed, err := ParseShare(string(BlocksOfFive([]byte(str))))
+		s.Require().NoError(err)
+		s.Equal(share.Index, parsed.Index)
+		s.Equal(share.Threshold, parsed.Threshold)
+		s.Equal(0, share.Value.Cmp(parsed.Value))
+	}
+	s.Equal(byte('Z'), shares[25].String()[0])
+	s.Equal(byte('B'), shares[25].String()[1])
+
+	typo := []byte(shares[0].String())
+	typo[20] = 'A' + (typo[20]-'A'+1)%26
+	_, err = ParseShare(string(typo))
+	s.ErrorContains(err, "typo")
+	_, err = ParseShare(shares[0].String()[:59])
+	s.Error(err)
+}
+
+func (s *ShamirSuite) TestParseShares() {
+	shares, err := SplitDeck(s.deck, 3, 2)
+	s.Require().NoError(err)
+	text := string(BlocksOfFive([]byte(shares[0].String()))) + "\n" + strings.ToLower(shares[2].String())
+	parsed, err := ParseShares(text)
+	s.Require().NoError(err)
+	s.Require().Len(parsed, 2)
+	s.Equal(shares[0].String(), parsed[0].String())
+	s.Equal(shares[2].String(), parsed[1].String())
+
+	_, err = ParseShares(text[:len(text)-1])
+	s.ErrorContains(err, "60 letters each")
+	_, err = ParseShares("")
+	s.Error(err)
+
+	typo := []byte(shares[1].String())
+	typo[10] = 'A' + (typo[10]-'A'+1)%26
+	_, err = ParseShares(shares[0].String() + string(typo))
+	s.ErrorContains(err, "share 2")
+}
+
+func TestShamir(t *testing.T) {
+	suite.Run(t, new(ShamirSuite))
+}
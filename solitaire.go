@@ -2,6 +2,8 @@ package solitaire
 
 import (
 	"fmt"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/awnumar/memguard"
 )
@@ -9,6 +11,19 @@ import (
 type solitaire struct {
 	// The deck of cards used in the Solitaire encryption algorithm.
 	deck *Deck
+	// preserveFormat keeps non-letters and the case of letters in place.
+	preserveFormat bool
+	// alphabet is the order of the letters used to add and subtract the keystream.
+	alphabet defaultAlphabet
+	// transpositions are the column orders of the transpositions applied
+	// to the ciphertext, in the order of encryption.
+	transpositions [][]int
+	// codebook replaces phrases by codes before encryption and expands them after decryption.
+	codebook *Codebook
+	// fingerprintHeader prepends the fingerprint of the deck to ciphertexts.
+	fingerprintHeader bool
+	// fingerprint is the fingerprint of the deck as set up by the options.
+	fingerprint string
 }
 
 type SolitaireOption func(*solitaire) error
@@ -25,12 +40,7 @@ func WithPassphrase(passphrase []byte) SolitaireOption {
 
 		s.deck = &Deck{}
 		copy(s.deck[:], initialDeck)
-
-		// Set the position to 0
-		for _, c := range passphrase {
-			s.deck.Advance()
-			s.deck.countCut(alphabet.Index(c) + 1)
-		}
+		s.deck.key(passphrase)
 		return nil
 	}
 }
@@ -72,8 +82,133 @@ func WithPassphraseFromEnclave(passphrase *memguard.Enclave) SolitaireOption {
 	}
 }
 
+// NormalizePassphrase returns the letters that WithNormalizedPassphrase keys
+// the deck with: the passphrase normalized like a cleartext, i.e. upper case,
+// umlauts and sharp S in UTF-8 or Latin-1 spelled out as AE, OE, UE and SS,
+// and all other characters, like spaces, digits and punctuation, removed.
+func NormalizePassphrase(passphrase []byte) []byte {
+	latin1 := latin1Umlauts(passphrase)
+	defer memguard.WipeBytes(latin1)
+	return normalizeCleartext(latin1)
+}
+
+// WithNormalizedPassphrase is like WithPassphrase, but keys the deck with the
+// letters of NormalizePassphrase, so that a memorable sentence like
+// "Meet me at 10, Grüße!" can be used: it is the same key as
+// "MEETMEATGRUESSE". Without normalization, every character other than a
+// letter is a count cut of zero. If the passphrase contains no letters,
+// it returns an error.
+func WithNormalizedPassphrase(passphrase []byte) SolitaireOption {
+	return func(s *solitaire) error {
+		letters := NormalizePassphrase(passphrase)
+		defer memguard.WipeBytes(letters)
+		if len(letters) == 0 {
+			return fmt.Errorf("passphrase must contain letters")
+		}
+		return WithPassphrase(letters)(s)
+	}
+}
+
+// WithNormalizedPassphraseFromEnclave is like WithNormalizedPassphrase, but
+// takes the passphrase from a memguard.Enclave.
+// If the memguard.Enclave cannot be opened, WithNormalizedPassphraseFromEnclave will panic.
+func WithNormalizedPassphraseFromEnclave(passphrase *memguard.Enclave) SolitaireOption {
+	return func(s *solitaire) error {
+		if passphrase == nil {
+			return fmt.Errorf("passphrase is required")
+		}
+		buf, err := passphrase.Open()
+		if err != nil {
+			memguard.SafePanic(err)
+		}
+		defer buf.Destroy()
+		return WithNormalizedPassphrase(buf.Bytes())(s)
+	}
+}
+
+// WithDeck sets the deck directly, e.g. to a deck imported with ParseDeck.
+// The deck is copied, so later changes to d do not affect the instance.
+// If the deck is nil or invalid, it returns an error.
+func WithDeck(d *Deck) SolitaireOption {
+	return func(s *solitaire) error {
+		if d == nil {
+			return fmt.Errorf("deck is required")
+		}
+		if err := d.Validate(); err != nil {
+			return fmt.Errorf("invalid deck: %w", err)
+		}
+		s.deck = &Deck{}
+		copy(s.deck[:], d[:])
+		return nil
+	}
+}
+
+// WithPreservedFormat makes Encrypt and Decrypt transform only the letters
+// A to Z and a to z, keeping their case, while all other characters stay in
+// place and no padding is added. Encrypt spells out umlauts and sharp S as
+// Ae, oe, ss and so on, and returns an error for other non-ASCII letters or
+// text that is not UTF-8, as it cannot encrypt them.
+//
+// The ciphertext leaks the structure of the plaintext: word lengths,
+// punctuation, capitalization and digits are all visible. Use it for teaching
+// and for notes of low value only.
+func WithPreservedFormat() SolitaireOption {
+	return func(s *solitaire) error {
+		s.preserveFormat = true
+		return nil
+	}
+}
+
+// WithAlphabetKey replaces the plain A to Z ordering used by Encrypt and
+// Decrypt with a mixed alphabet built from the keyword: the letters of the
+// keyword first, then the remaining letters. The keyword is a second secret
+// besides the deck; it does not affect the keystream.
+// If the keyword contains no letters, it returns an error.
+func WithAlphabetKey(keyword []byte) SolitaireOption {
+	return func(s *solitaire) error {
+		t, err := keyedAlphabet(keyword)
+		if err != nil {
+			return err
+		}
+		s.alphabet = t
+		return nil
+	}
+}
+
+// WithAlphabetKeyFromEnclave is like WithAlphabetKey, but takes the keyword
+// from a memguard.Enclave.
+// If the memguard.Enclave cannot be opened, WithAlphabetKeyFromEnclave will panic.
+func WithAlphabetKeyFromEnclave(keyword *memguard.Enclave) SolitaireOption {
+	return func(s *solitaire) error {
+		if keyword == nil {
+			return fmt.Errorf("alphabet key is required")
+		}
+		buf, err := keyword.Open()
+		if err != nil {
+			memguard.SafePanic(err)
+		}
+		defer buf.Destroy()
+		return WithAlphabetKey(buf.Bytes())(s)
+	}
+}
+
+// WithCodebook makes Encrypt replace the phrases of the codebook in the
+// cleartext by their codes before normalizing it, and Decrypt expand the codes
+// in the plaintext back into the phrases. The plaintext returned by Decrypt is
+// then no longer split into blocks of five.
+// The codebook cannot be combined with WithPreservedFormat or Repair.
+func WithCodebook(c *Codebook) SolitaireOption {
+	return func(s *solitaire) error {
+		if c == nil {
+			return fmt.Errorf("codebook is required")
+		}
+		s.codebook = c
+		return nil
+	}
+}
+
 func New(opts ...SolitaireOption) (*solitaire, error) {
-	s := &solitaire{}
+	s := &solitaire{alphabet: alphabet}
 	for _, opt := range opts {
 		if err := opt(s); err != nil {
 			return nil, err
@@ -82,6 +217,18 @@ func New(opts ...SolitaireOption) (*solitaire, error) {
 	if s.deck == nil {
 		return nil, fmt.Errorf("deck is required")
 	}
+	if s.preserveFormat && s.transpositions != nil {
+		return nil, fmt.Errorf("transposition cannot be combined with a preserved format")
+	}
+	if s.preserveFormat && s.codebook != nil {
+		return nil, fmt.Errorf("codebook cannot be combined with a preserved format")
+	}
+	if s.fingerprintHeader {
+		if s.preserveFormat {
+			return nil, fmt.Errorf("fingerprint header cannot be combined with a preserved format")
+		}
+		s.fingerprint = s.deck.Fingerprint()
+	}
 	return s, nil
 }
 
@@ -92,9 +239,37 @@ func (s *solitaire) Deck() []Card {
 	return d
 }
 
+// Alphabet returns the order of the letters used by Encrypt and Decrypt,
+// e.g. to write down the table for encrypting by hand.
+func (s *solitaire) Alphabet() string {
+	return string(s.alphabet[:])
+}
+
+// Encrypt encrypts the letters of the plaintext and returns them in blocks of
+// five. The plaintext is upper-cased, the umlauts and sharp S in UTF-8 or
+// Latin-1 are spelled out as AE, OE, UE and SS, all other characters are
+// dropped, and it is padded with X to a multiple of five letters.
 func (s *solitaire) Encrypt(plaintext []byte) ([]byte, error) {
+	if s.preserveFormat {
+		spelled, err := spellUmlauts(plaintext)
+		if err != nil {
+			return nil, err
+		}
+		return s.transformLetters(spelled, s.alphabet.encrypt), nil
+	}
+	if s.codebook != nil {
+		plaintext = s.codebook.Encode(plaintext)
+	}
 	// Normalize the plaintext by removing spaces and converting to uppercase.
-	normalized := normalizeCleartext(padClearText(plaintext))
+	// Pad only after that, as letters like é are dropped and umlauts are
+	// spelled out as two letters.
+	normalized := padClearText(normalizeCleartext(latin1Umlauts(plaintext)))
+	return BlocksOfFive(s.addHeader(s.encryptLetters(normalized))), nil
+}
+
+// encryptLetters encrypts the normalized letters with the keystream and
+// applies the transpositions, if any.
+func (s *solitaire) encryptLetters(normalized []byte) []byte {
 	keys := s.generateKeyStream(len(normalized))
 
 	// Encrypt the plaintext using the keystream.
@@ -102,37 +277,123 @@ func (s *solitaire) Encrypt(plaintext []byte) ([]byte, error) {
 	// The character at that index is used to encrypt the plaintext.
 	ct := make([]byte, len(normalized))
 	for i, c := range normalized {
-		n := alphabet.Index(c)
-		key := keys[i]
-		idx := (n + key + 1) % len(alphabet)
-		ct[i] = alphabet.Char(idx)
+		ct[i] = s.alphabet.encrypt(c, keys[i])
 	}
-
-	return BlocksOfFive(ct), nil
+	return s.superencipher(ct)
 }
 
 func (s *solitaire) Decrypt(ciphertext []byte) ([]byte, error) {
+	if s.preserveFormat {
+		return s.transformLetters(ciphertext, s.alphabet.decrypt), nil
+	}
 	cleaned := nonLetters.ReplaceAll(ciphertext, []byte(""))
 	// Normalize the ciphertext by removing spaces and converting to uppercase.
 	if len(cleaned) == 0 || len(cleaned)%5 != 0 {
 		// If the ciphertext is empty or not a multiple of 5, PANIC!
 		panic("ciphertext must be a non-empty multiple of 5")
 	}
+	cleaned, err := s.checkHeader(cleaned)
+	if err != nil {
+		return nil, err
+	}
+	ct := s.decryptLetters(cleaned)
+	if s.codebook != nil {
+		return s.codebook.Expand(ct), nil
+	}
+	return BlocksOfFive(ct), nil
+}
+
+// decryptLetters reverses the transpositions, if any, and decrypts the
+// letters with the keystream.
+func (s *solitaire) decryptLetters(cleaned []byte) []byte {
+	cleaned = s.removeSuperencipherment(cleaned)
 	// Generate the keystream
 	keys := s.generateKeyStream(len(cleaned))
 
 	// Decrypt the ciphertext using the keystream.
-	ct := make([]byte, len(cleaned))
+	pt := make([]byte, len(cleaned))
 	for i, c := range cleaned {
-		n := alphabet.Index(c)
-		key := keys[i]
-		idx := (n - key + 1) % len(alphabet)
-		if idx < 0 {
-			idx += len(alphabet)
+		pt[i] = s.alphabet.decrypt(c, keys[i])
+	}
+	return pt
+}
+
+// transformLetters applies f with consecutive keystream values to the ASCII
+// letters of text, keeping their case. All other bytes are copied unchanged.
+func (s *solitaire) transformLetters(text []byte, f func(c byte, key int) byte) []byte {
+	letters := 0
+	for _, c := range text {
+		if isASCIILetter(c) {
+			letters++
 		}
-		ct[i] = alphabet.Char(idx)
 	}
-	return BlocksOfFive(ct), nil
+	keys := s.generateKeyStream(letters)
+
+	out := make([]byte, len(text))
+	k := 0
+	for i, c := range text {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			out[i] = f(c, keys[k])
+			k++
+		case c >= 'a' && c <= 'z':
+			out[i] = f(c-'a'+'A', keys[k]) - 'A' + 'a'
+			k++
+		default:
+			out[i] = c
+		}
+	}
+	return out
+}
+
+// umlautSpellings keep the case of the umlauts and sharp S they spell out.
+var umlautSpellings = map[rune]string{
+	'Ä': "Ae", 'Ö': "Oe", 'Ü': "Ue",
+	'ä': "ae", 'ö': "oe", 'ü': "ue",
+	'ß': "ss",
+}
+
+// spellUmlauts returns text with the umlauts and sharp S spelled out, so that
+// transformLetters encrypts them. Other non-ASCII letters would be left in
+// clear, so it returns an error for them, as well as for invalid UTF-8.
+func spellUmlauts(text []byte) ([]byte, error) {
+	out := make([]byte, 0, len(text))
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRune(text[i:])
+		switch spelling, exists := umlautSpellings[r]; {
+		case exists:
+			out = append(out, spelling...)
+		case r == utf8.RuneError && size == 1:
+			return nil, fmt.Errorf("invalid UTF-8 at byte %d", i)
+		case r >= utf8.RuneSelf && unicode.IsLetter(r):
+			return nil, fmt.Errorf("cannot encrypt the letter %q at byte %d with a preserved format", r, i)
+		default:
+			out = append(out, text[i:i+size]...)
+		}
+		i += size
+	}
+	return out, nil
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+// encrypt encrypts the letter c with the keystream value key.
+func (t defaultAlphabet) encrypt(c byte, key int) byte {
+	n := t.Index(c)
+	idx := (n + key + 1) % len(t)
+	return t.Char(idx)
+}
+
+// decrypt decrypts the letter c with the keystream value key.
+func (t defaultAlphabet) decrypt(c byte, key int) byte {
+	n := t.Index(c)
+	idx := (n - key + 1) % len(t)
+	if idx < 0 {
+		idx += len(t)
+	}
+	return t.Char(idx)
 }
 
 func (s *solitaire) generateKeyStream(length int) []int {
@@ -140,7 +401,7 @@ func (s *solitaire) generateKeyStream(length int) []int {
 	keys := make([]int, 0)
 	for i := 0; len(keys) < length; i++ {
 		s.deck.Advance()
-		val := s.deck[s.deck[0].Value()].Value()
+		val := s.deck.Output()
 		if val >= 53 {
 			// Skip the jokers
 			continue
@@ -48,6 +48,40 @@ func TestEncryption(t *testing.T) {
 	}
 }
 
+func TestEncryptionUmlauts(t *testing.T) {
+	s, err := solitaire.New(solitaire.WithPassphrase([]byte("CRYPTONOMICON")))
+	assert.NoError(t, err)
+	umlauts, err := s.Encrypt([]byte("Grüße aus Köln, ÄÖÜ"))
+	assert.NoError(t, err)
+	assert.Equal(t, "YLAWJ KBSQI BABZU ITPJJ\nBXOVN", string(umlauts))
+
+	s, err = solitaire.New(solitaire.WithPassphrase([]byte("CRYPTONOMICON")))
+	assert.NoError(t, err)
+	spelled, err := s.Encrypt([]byte("GRUESSE AUS KOELN AEOEUE"))
+	assert.NoError(t, err)
+	assert.Equal(t, string(spelled), string(umlauts))
+
+	s, err = solitaire.New(solitaire.WithPassphrase([]byte("CRYPTONOMICON")))
+	assert.NoError(t, err)
+	pt, err := s.Decrypt(umlauts)
+	assert.NoError(t, err)
+	assert.Equal(t, "GRUES SEAUS KOELN AEOEU\nEXXXX", string(pt))
+}
+
+func TestEncryptionDroppedLetters(t *testing.T) {
+	s, err := solitaire.New(solitaire.WithPassphrase([]byte("CRYPTONOMICON")))
+	assert.NoError(t, err)
+	ct, err := s.Encrypt([]byte("Café crème"))
+	assert.NoError(t, err)
+	assert.Equal(t, "UULUI EBPTN", string(ct), "The padding counts the letters that are left")
+
+	s, err = solitaire.New(solitaire.WithPassphrase([]byte("CRYPTONOMICON")))
+	assert.NoError(t, err)
+	pt, err := s.Decrypt(ct)
+	assert.NoError(t, err)
+	assert.Equal(t, "CAFCR MEXXX", string(pt))
+}
+
 func TestDecryption(t *testing.T) {
 	testCases := []struct {
 		passphrase string
@@ -78,6 +112,67 @@ func TestDecryption(t *testing.T) {
 	}
 }
 
+func TestPreservedFormat(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		cleartext string
+		plaintext string
+	}{
+		{desc: "letters only", cleartext: "SOLITAIRE"},
+		{desc: "mixed case and punctuation", cleartext: "Hello, World! Meet me at 10:30."},
+		{desc: "umlauts are spelled out", cleartext: "Grüße aus Köln, Ärger", plaintext: "Gruesse aus Koeln, Aerger"},
+		{desc: "no letters", cleartext: "12345 !?"},
+		{desc: "empty", cleartext: ""},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if tC.plaintext == "" {
+				tC.plaintext = tC.cleartext
+			}
+			s, err := solitaire.New(solitaire.WithPassphrase([]byte("CRYPTONOMICON")), solitaire.WithPreservedFormat())
+			assert.NoError(t, err)
+			ct, err := s.Encrypt([]byte(tC.cleartext))
+			assert.NoError(t, err)
+			assert.Len(t, ct, len(tC.plaintext))
+			for i := range ct {
+				c, p := ct[i], tC.plaintext[i]
+				switch {
+				case p >= 'A' && p <= 'Z':
+					assert.True(t, c >= 'A' && c <= 'Z', "position %d should be uppercase", i)
+				case p >= 'a' && p <= 'z':
+					assert.True(t, c >= 'a' && c <= 'z', "position %d should be lowercase", i)
+				default:
+					assert.Equal(t, p, c, "position %d should be unchanged", i)
+				}
+			}
+
+			s, err = solitaire.New(solitaire.WithPassphrase([]byte("CRYPTONOMICON")), solitaire.WithPreservedFormat())
+			assert.NoError(t, err)
+			pt, err := s.Decrypt(ct)
+			assert.NoError(t, err)
+			assert.Equal(t, tC.plaintext, string(pt))
+		})
+	}
+}
+
+func TestPreservedFormatRefusesOtherLetters(t *testing.T) {
+	for _, cleartext := range []string{"Crème brûlée", "Ελληνικά", "Gr\xfc\xdfe"} {
+		s, err := solitaire.New(solitaire.WithPassphrase([]byte("CRYPTONOMICON")), solitaire.WithPreservedFormat())
+		assert.NoError(t, err)
+		_, err = s.Encrypt([]byte(cleartext))
+		assert.Error(t, err, cleartext)
+	}
+}
+
+func TestPreservedFormatMatchesKeystream(t *testing.T) {
+	s, err := solitaire.New(solitaire.WithPassphrase([]byte("CRYPTONOMICON")), solitaire.WithPreservedFormat())
+	assert.NoError(t, err)
+	ct, err := s.Encrypt([]byte("Soli-taire"))
+	assert.NoError(t, err)
+	// The letters are encrypted like in the normal mode, see TestEncryption.
+	assert.Equal(t, "Kira-ksfja", string(ct))
+}
+
 func TestSolitaire(t *testing.T) {
 	s, err := solitaire.New(solitaire.WithPassphrase([]byte("CRYPTONOMICON")))
 	assert.NoError(t, err, "Failed to create new solitaire instance")
@@ -89,3 +184,47 @@ func TestSolitaire(t *testing.T) {
 	assert.Equal(t, result, ct, "Ciphertext does not match expected value")
 
 }
+
+func TestAlphabetKey(t *testing.T) {
+	s, err := solitaire.New(solitaire.WithPassphrase([]byte("CRYPTONOMICON")), solitaire.WithAlphabetKey([]byte("Key word")))
+	assert.NoError(t, err)
+	assert.Equal(t, "KEYWORDABCFGHIJLMNPQSTUVXZ", s.Alphabet())
+	ct, err := s.Encrypt([]byte("SOLITAIRE"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, "KIRAK SFJAN", string(ct), "The mixed alphabet should change the ciphertext")
+
+	s, err = solitaire.New(solitaire.WithPassphrase([]byte("CRYPTONOMICON")), solitaire.WithAlphabetKey([]byte("KEYWORD")))
+	assert.NoError(t, err)
+	pt, err := s.Decrypt(ct)
+	assert.NoError(t, err)
+	assert.Equal(t, "SOLIT AIREX", string(pt))
+
+	s, err = solitaire.New(solitaire.WithPassphrase([]byte("CRYPTONOMICON")))
+	assert.NoError(t, err)
+	assert.Equal(t, "ABCDEFGHIJKLMNOPQRSTUVWXYZ", s.Alphabet())
+	pt, err = s.Decrypt(ct)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "SOLIT AIREX", string(pt))
+}
+
+func TestAlphabetKeyInvalid(t *testing.T) {
+	_, err := solitaire.New(solitaire.WithPassphrase([]byte("CRYPTONOMICON")), solitaire.WithAlphabetKey([]byte("123")))
+	assert.Error(t, err)
+}
+
+func TestNormalizedPassphrase(t *testing.T) {
+	assert.Equal(t, "MEETMEATGRUESSE", string(solitaire.NormalizePassphrase([]byte("Meet me at 10, Grüße!"))))
+
+	s, err := solitaire.New(solitaire.WithNormalizedPassphrase([]byte("Meet me at 10, Grüße!")))
+	assert.NoError(t, err)
+	letters, err := solitaire.New(solitaire.WithPassphrase([]byte("MEETMEATGRUESSE")))
+	assert.NoError(t, err)
+	assert.Equal(t, letters.Deck(), s.Deck())
+
+	raw, err := solitaire.New(solitaire.WithPassphrase([]byte("Meet me at 10, Grüße!")))
+	assert.NoError(t, err)
+	assert.NotEqual(t, raw.Deck(), s.Deck(), "Without normalization, other characters are cuts of zero")
+
+	_, err = solitaire.New(solitaire.WithNormalizedPassphrase([]byte("1234 !")))
+	assert.Error(t, err)
+}
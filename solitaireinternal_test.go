@@ -38,6 +38,19 @@ func (s *SolitaireSuite) TestWithPassphraseFromInvalidEnclave() {
 	}, "Expected panic when enclave is invalid")
 }
 
+func (s *SolitaireSuite) TestKeyedAlphabet() {
+	t, err := keyedAlphabet([]byte("zebras"))
+	s.NoError(err)
+	s.Equal("ZEBRASCDFGHIJKLMNOPQTUVWXY", string(t[:]))
+	for i, c := range t {
+		s.Equal(i, t.Index(c))
+		s.Equal(c, t.Char(i+1))
+	}
+	for key := 1; key <= 52; key++ {
+		s.Equal(byte('Q'), t.decrypt(t.encrypt('Q', key), key))
+	}
+}
+
 func TestSolitaireInternal(t *testing.T) {
 	suite.Run(t, new(SolitaireSuite))
 }
@@ -0,0 +1,87 @@
+package solitaire
+
+import (
+	"fmt"
+	"math/bits"
+	"math/rand/v2"
+)
+
+// Source is a math/rand/v2.Source driven by the Solitaire keystream, for
+// shuffles and draws that can be reproduced and verified by everyone knowing
+// the passphrase or deck.
+//
+// Source is NOT a cryptographically strong random number generator: the
+// Solitaire keystream has known biases and a deck can be recovered from
+// enough of its output. Do not use it for keys, nonces or anything secret.
+type Source struct {
+	deck Deck
+}
+
+var _ rand.Source = (*Source)(nil)
+
+// NewSource returns a source producing the keystream of a copy of the deck.
+// If the deck is nil or invalid, it returns an error.
+func NewSource(d *Deck) (*Source, error) {
+	if d == nil {
+		return nil, fmt.Errorf("deck is required")
+	}
+	if err := d.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid deck: %w", err)
+	}
+	return &Source{deck: *d}, nil
+}
+
+// Source returns a source producing the keystream from the current state of
+// the deck. The instance and the source do not affect each other.
+func (s *solitaire) Source() *Source {
+	return &Source{deck: *s.deck}
+}
+
+// nibble returns four uniformly distributed bits. It advances the deck until
+// the output card is one of the 48 cards with the values 1 to 48, rejecting
+// the values 49 to 52 and the jokers, so that the value modulo 16 is unbiased
+// with respect to the card values.
+func (src *Source) nibble() uint64 {
+	for {
+		src.deck.Advance()
+		if v := src.deck.Output(); v <= 48 {
+			return uint64(v % 16)
+		}
+	}
+}
+
+// Uint64 returns 64 bits taken from sixteen keystream values.
+func (src *Source) Uint64() uint64 {
+	var v uint64
+	for range 16 {
+		v = v<<4 | src.nibble()
+	}
+	return v
+}
+
+// Shuffle pseudo-randomizes the order of n elements with values from src.
+// swap swaps the elements with indexes i and j.
+//
+// Unlike rand.Shuffle, the algorithm is fixed, so a shuffle from a Source can
+// be reproduced with any Go version: a Fisher-Yates shuffle from the last
+// element down, drawing each index by rejection sampling of the smallest
+// number of bits covering its range.
+func Shuffle(src rand.Source, n int, swap func(i, j int)) {
+	if n < 0 {
+		panic("invalid argument to Shuffle")
+	}
+	for i := n - 1; i > 0; i-- {
+		swap(i, uniform(src, i+1))
+	}
+}
+
+// uniform returns a uniformly distributed number in [0, n) with values from
+// src, using whole 64-bit values for each draw.
+func uniform(src rand.Source, n int) int {
+	mask := uint64(1)<<bits.Len64(uint64(n-1)) - 1
+	for {
+		if v := src.Uint64() & mask; v < uint64(n) {
+			return int(v)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package solitaire
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SourceSuite struct {
+	suite.Suite
+}
+
+func (s *SourceSuite) newSource() *Source {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	return sol.Source()
+}
+
+func (s *SourceSuite) TestReproducible() {
+	a, b := s.newSource(), s.newSource()
+	for range 10 {
+		s.Equal(a.Uint64(), b.Uint64())
+	}
+	s.NotEqual(s.newSource().Uint64(), a.Uint64())
+}
+
+func (s *SourceSuite) TestNewSource() {
+	d := Deck{}
+	copy(d[:], initialDeck)
+	src, err := NewSource(&d)
+	s.Require().NoError(err)
+	d.Advance()
+	sol, err := New(WithPassphrase([]byte("")))
+	s.Require().NoError(err)
+	s.Equal(sol.Source().Uint64(), src.Uint64(), "The source does not share the deck")
+
+	_, err = NewSource(nil)
+	s.Error(err)
+	_, err = NewSource(&Deck{})
+	s.Error(err)
+}
+
+func (s *SourceSuite) TestNibbles() {
+	src := s.newSource()
+	counts := make([]int, 16)
+	for range 1000 {
+		v := src.Uint64()
+		for range 16 {
+			counts[v&15]++
+			v >>= 4
+		}
+	}
+	for i, c := range counts {
+		s.InDelta(1000, c, 150, "nibble %d", i)
+	}
+}
+
+func (s *SourceSuite) TestRand() {
+	r := rand.New(s.newSource())
+	for range 100 {
+		n := r.IntN(52)
+		s.GreaterOrEqual(n, 0)
+		s.Less(n, 52)
+	}
+}
+
+func (s *SourceSuite) TestShuffle() {
+	shuffle := func() []int {
+		cards := make([]int, 52)
+		for i := range cards {
+			cards[i] = i
+		}
+		Shuffle(s.newSource(), len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+		return cards
+	}
+	cards := shuffle()
+	s.Equal(cards, shuffle())
+	s.ElementsMatch(cards, shuffle())
+	s.NotEqual(shuffle()[:10], []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+
+	// The shuffle must not change between versions, or shuffles can no longer be verified.
+	small := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	Shuffle(s.newSource(), len(small), func(i, j int) { small[i], small[j] = small[j], small[i] })
+	s.Equal([]int{9, 4, 6, 2, 7, 5, 1, 0, 3, 8}, small)
+
+	Shuffle(s.newSource(), 0, func(i, j int) { s.Fail("swap called") })
+	s.Panics(func() { Shuffle(s.newSource(), -1, func(i, j int) {}) })
+}
+
+func TestSource(t *testing.T) {
+	suite.Run(t, new(SourceSuite))
+}
@@ -0,0 +1,197 @@
+package solitaire
+
+import (
+	"math"
+)
+
+// KeystreamStats holds the results of statistical tests on keystream values.
+// All tests are performed on the keystream values reduced to letters, i.e.
+// modulo 26, as they are used by Encrypt and Decrypt. Tests on consecutive
+// values never cross the boundary between the keystreams of two keys.
+type KeystreamStats struct {
+	// Keys is the number of keys the keystreams were generated from.
+	Keys int `json:"keys"`
+	// Values is the total number of keystream values analyzed.
+	Values int `json:"values"`
+
+	// Frequencies holds the number of occurrences of each letter A to Z,
+	// where A stands for a shift of zero.
+	Frequencies [26]int `json:"frequencies"`
+	// ChiSquare is the chi-square statistic of the letter frequencies
+	// against a uniform distribution, with 25 degrees of freedom.
+	ChiSquare float64 `json:"chi_square"`
+	// ChiSquareP is the probability of a chi-square statistic at least as
+	// large as ChiSquare for a uniform source.
+	ChiSquareP float64 `json:"chi_square_p"`
+
+	// Repeats is the number of consecutive value pairs that are equal.
+	Repeats int `json:"repeats"`
+	// RepeatRate is the fraction of consecutive value pairs that are equal.
+	RepeatRate float64 `json:"repeat_rate"`
+	// ExpectedRepeatRate is the repeat rate of a uniform source, 1/26.
+	ExpectedRepeatRate float64 `json:"expected_repeat_rate"`
+	// RepeatZ is the z-score of the number of repeats.
+	RepeatZ float64 `json:"repeat_z"`
+
+	// SerialCorrelation is the correlation coefficient between consecutive values.
+	SerialCorrelation float64 `json:"serial_correlation"`
+
+	// Runs is the number of runs above and below the median letter.
+	Runs int `json:"runs"`
+	// ExpectedRuns is the number of runs expected for a random source.
+	ExpectedRuns float64 `json:"expected_runs"`
+	// RunsZ is the z-score of the number of runs.
+	RunsZ float64 `json:"runs_z"`
+
+	// Digrams holds the number of occurrences of each pair of consecutive letters,
+	// indexed by the first and the second letter.
+	Digrams [26][26]int `json:"digrams"`
+}
+
+// AnalyzeKeystream generates n keystream values for each of the given decks
+// and runs the statistical tests on them.
+// The decks are not modified.
+func AnalyzeKeystream(decks []Deck, n int) KeystreamStats {
+	stats := KeystreamStats{
+		Keys:               len(decks),
+		ExpectedRepeatRate: 1.0 / 26,
+	}
+
+	var (
+		pairs                           int
+		sumX, sumY, sumXX, sumYY, sumXY float64
+		expectedRuns, runsVariance      float64
+	)
+	for _, d := range decks {
+		s := &solitaire{deck: &Deck{}}
+		copy(s.deck[:], d[:])
+		keys := s.generateKeyStream(n)
+		letters := make([]int, len(keys))
+		for i, k := range keys {
+			letters[i] = k % 26
+			stats.Frequencies[letters[i]]++
+		}
+		stats.Values += len(letters)
+
+		above := 0
+		for i, l := range letters {
+			if l >= 13 {
+				above++
+			}
+			if i == 0 {
+				stats.Runs++
+				continue
+			}
+			prev := letters[i-1]
+			if (prev >= 13) != (l >= 13) {
+				stats.Runs++
+			}
+			if prev == l {
+				stats.Repeats++
+			}
+			stats.Digrams[prev][l]++
+			pairs++
+			x, y := float64(prev), float64(l)
+			sumX += x
+			sumY += y
+			sumXX += x * x
+			sumYY += y * y
+			sumXY += x * y
+		}
+
+		// Wald-Wolfowitz runs test, summed over the independent keystreams.
+		n1, n2 := float64(above), float64(len(letters)-above)
+		total := n1 + n2
+		if total > 1 {
+			expectedRuns += 2*n1*n2/total + 1
+			runsVariance += 2 * n1 * n2 * (2*n1*n2 - total) / (total * total * (total - 1))
+		}
+	}
+
+	if stats.Values > 0 {
+		expected := float64(stats.Values) / 26
+		for _, f := range stats.Frequencies {
+			diff := float64(f) - expected
+			stats.ChiSquare += diff * diff / expected
+		}
+		stats.ChiSquareP = chiSquareSurvival(stats.ChiSquare, 25)
+	}
+
+	if pairs > 0 {
+		p := stats.ExpectedRepeatRate
+		stats.RepeatRate = float64(stats.Repeats) / float64(pairs)
+		stats.RepeatZ = (float64(stats.Repeats) - float64(pairs)*p) / math.Sqrt(float64(pairs)*p*(1-p))
+
+		n := float64(pairs)
+		cov := sumXY/n - (sumX/n)*(sumY/n)
+		varX := sumXX/n - (sumX/n)*(sumX/n)
+		varY := sumYY/n - (sumY/n)*(sumY/n)
+		if varX > 0 && varY > 0 {
+			stats.SerialCorrelation = cov / math.Sqrt(varX*varY)
+		}
+	}
+
+	stats.ExpectedRuns = expectedRuns
+	if runsVariance > 0 {
+		stats.RunsZ = (float64(stats.Runs) - expectedRuns) / math.Sqrt(runsVariance)
+	}
+	return stats
+}
+
+// chiSquareSurvival returns the probability that a chi-square distributed
+// random variable with k degrees of freedom is at least x.
+func chiSquareSurvival(x float64, k int) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return upperIncompleteGamma(float64(k)/2, x/2)
+}
+
+// upperIncompleteGamma returns the regularized upper incomplete gamma function Q(a, x).
+// It uses the series expansion for x < a+1 and the continued fraction otherwise.
+func upperIncompleteGamma(a, x float64) float64 {
+	const (
+		iterations = 500
+		epsilon    = 1e-14
+		tiny       = 1e-300
+	)
+	lgamma, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lgamma)
+
+	if x < a+1 {
+		sum, term := 1/a, 1/a
+		for n := 1; n < iterations; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*epsilon {
+				break
+			}
+		}
+		return 1 - sum*prefix
+	}
+
+	// Lentz's method for the continued fraction.
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < iterations; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return h * prefix
+}
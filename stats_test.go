@@ -0,0 +1,79 @@
+package solitaire
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type StatsSuite struct {
+	suite.Suite
+}
+
+func (s *StatsSuite) TestChiSquareSurvival() {
+	testCases := []struct {
+		desc     string
+		x        float64
+		k        int
+		expected float64
+	}{
+		{desc: "zero", x: 0, k: 25, expected: 1},
+		{desc: "median region", x: 24.34, k: 25, expected: 0.5},
+		{desc: "5% critical value", x: 37.652, k: 25, expected: 0.05},
+		{desc: "1% critical value", x: 44.314, k: 25, expected: 0.01},
+		{desc: "small df", x: 3.841, k: 1, expected: 0.05},
+	}
+	for _, tC := range testCases {
+		s.Run(tC.desc, func() {
+			s.InDelta(tC.expected, chiSquareSurvival(tC.x, tC.k), 1e-3)
+		})
+	}
+}
+
+func (s *StatsSuite) TestAnalyzeKeystreamCounts() {
+	decks := make([]Deck, 3)
+	for i, p := range []string{"FOO", "BAR", "CRYPTONOMICON"} {
+		sol, err := New(WithPassphrase([]byte(p)))
+		s.Require().NoError(err)
+		decks[i] = *sol.deck
+	}
+	original := decks[0]
+
+	stats := AnalyzeKeystream(decks, 500)
+	s.Equal(3, stats.Keys)
+	s.Equal(1500, stats.Values)
+	s.Equal(original, decks[0], "Expected decks to be unchanged")
+
+	total := 0
+	for _, f := range stats.Frequencies {
+		total += f
+	}
+	s.Equal(stats.Values, total)
+
+	digrams := 0
+	for _, row := range stats.Digrams {
+		for _, c := range row {
+			digrams += c
+		}
+	}
+	s.Equal(stats.Values-stats.Keys, digrams, "Expected digrams not to cross key boundaries")
+	s.InDelta(1.0/26, stats.ExpectedRepeatRate, 1e-12)
+	s.Greater(stats.ChiSquareP, 0.0)
+	s.LessOrEqual(stats.ChiSquareP, 1.0)
+}
+
+func (s *StatsSuite) TestAnalyzeKeystreamMatchesEncryption() {
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	stats := AnalyzeKeystream([]Deck{*sol.deck}, 1)
+
+	// Encrypting A shifts it by the first keystream letter.
+	ct, err := sol.Encrypt([]byte("AAAAA"))
+	s.Require().NoError(err)
+	letter := int(ct[0] - 'A')
+	s.Equal(1, stats.Frequencies[letter])
+}
+
+func TestStats(t *testing.T) {
+	suite.Run(t, new(StatsSuite))
+}
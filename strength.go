@@ -0,0 +1,335 @@
+package solitaire
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// StrengthLevel rates the estimated entropy of a passphrase.
+type StrengthLevel int
+
+const (
+	// StrengthWeak is below 64 bits, within reach of a determined attacker
+	// trying passphrases offline.
+	StrengthWeak StrengthLevel = iota
+	// StrengthFair is at least 64 bits.
+	StrengthFair
+	// StrengthGood is at least 96 bits.
+	StrengthGood
+	// StrengthStrong is at least 128 bits, out of reach of exhaustive search.
+	StrengthStrong
+	// StrengthFull is at least DeckEntropy, as strong as a random deck.
+	StrengthFull
+)
+
+// strengthBits are the lower bounds of the levels in bits.
+var strengthBits = [...]float64{StrengthWeak: 0, StrengthFair: 64, StrengthGood: 96, StrengthStrong: 128, StrengthFull: DeckEntropy}
+
+var strengthNames = [...]string{StrengthWeak: "weak", StrengthFair: "fair", StrengthGood: "good", StrengthStrong: "strong", StrengthFull: "full"}
+
+func (l StrengthLevel) String() string {
+	if l < StrengthWeak || l > StrengthFull {
+		panic("invalid strength level")
+	}
+	return strengthNames[l]
+}
+
+// Bits returns the lower bound of the level in bits.
+func (l StrengthLevel) Bits() float64 {
+	return strengthBits[l]
+}
+
+// ParseStrengthLevel parses the name of a level as returned by String.
+func ParseStrengthLevel(s string) (StrengthLevel, error) {
+	for l, name := range strengthNames {
+		if strings.EqualFold(s, name) {
+			return StrengthLevel(l), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown strength level %q, expected one of %s", s, strings.Join(strengthNames[:], ", "))
+}
+
+// WeaknessKind is the kind of a guessable part of a passphrase.
+type WeaknessKind int
+
+const (
+	// WeaknessWord is a word of a registered dictionary.
+	WeaknessWord WeaknessKind = iota
+	// WeaknessRepeat repeats earlier characters, like "AAAA" or "ABCABC".
+	WeaknessRepeat
+	// WeaknessSequence is a run of the alphabet or the digits, like "ABCD"
+	// or "4321".
+	WeaknessSequence
+	// WeaknessKeyboardWalk is a run of neighbouring keys on a QWERTY or
+	// QWERTZ keyboard, like "QWERTZ" or "ASDF".
+	WeaknessKeyboardWalk
+	// WeaknessZeroCut are characters other than A to Z, including lower-case
+	// letters, which WithPassphrase keys as a count cut of zero.
+	WeaknessZeroCut
+)
+
+func (k WeaknessKind) String() string {
+	switch k {
+	case WeaknessWord:
+		return "dictionary word"
+	case WeaknessRepeat:
+		return "repeat"
+	case WeaknessSequence:
+		return "sequence"
+	case WeaknessKeyboardWalk:
+		return "keyboard walk"
+	case WeaknessZeroCut:
+		return "zero cut"
+	default:
+		panic("invalid weakness kind")
+	}
+}
+
+// PassphraseWeakness is a guessable part of a passphrase.
+type PassphraseWeakness struct {
+	Kind WeaknessKind
+	// Text is the part of the passphrase.
+	Text string
+	// Position is the index of the first character of the part. It counts
+	// bytes for EstimateStrength and characters for EstimateTextStrength.
+	Position int
+	// Bits is the entropy the part contributes to the estimate.
+	Bits float64
+}
+
+func (w PassphraseWeakness) String() string {
+	return fmt.Sprintf("%s %q at %d: %.1f bits", w.Kind, w.Text, w.Position, w.Bits)
+}
+
+// PassphraseStrength is the result of EstimateStrength.
+type PassphraseStrength struct {
+	// Entropy is the estimated entropy of the passphrase in bits.
+	Entropy float64
+	// Level rates the entropy, at most as strong as the deck it keys.
+	Level StrengthLevel
+	// Weaknesses are the guessable parts of the passphrase, in order.
+	Weaknesses []PassphraseWeakness
+}
+
+// Deck returns the share of the entropy of a random deck, DeckEntropy, that
+// a deck keyed from the passphrase reaches, at most 1.
+func (p PassphraseStrength) Deck() float64 {
+	return min(p.Entropy, DeckEntropy) / DeckEntropy
+}
+
+// sequences are the runs of characters that count as sequences or keyboard
+// walks, forwards and backwards.
+var sequences = []struct {
+	kind  WeaknessKind
+	chars string
+}{
+	{WeaknessSequence, "ABCDEFGHIJKLMNOPQRSTUVWXYZ"},
+	{WeaknessSequence, "0123456789"},
+	{WeaknessKeyboardWalk, "1234567890"},
+	{WeaknessKeyboardWalk, "QWERTYUIOP"},
+	{WeaknessKeyboardWalk, "QWERTZUIOP"},
+	{WeaknessKeyboardWalk, "ASDFGHJKL"},
+	{WeaknessKeyboardWalk, "ZXCVBNM"},
+	{WeaknessKeyboardWalk, "YXCVBNM"},
+}
+
+const (
+	// minPatternLength is the length from which repeats, sequences and
+	// keyboard walks are detected.
+	minPatternLength = 3
+	// maxPatternLength bounds the length of words and repeats, so that the
+	// estimate of long passphrases takes linear time. Longer repeats are
+	// split into several.
+	maxPatternLength = 64
+	// maxSequenceLength is the length of the longest sequence.
+	maxSequenceLength = 26
+	// maxRepeatCandidates is the number of the latest earlier positions
+	// checked for a repeat.
+	maxRepeatCandidates = 16
+)
+
+// EstimateStrength estimates the entropy of a passphrase for WithPassphrase
+// as the number of bits an attacker needs to guess it with knowledge of
+// common patterns.
+//
+// The passphrase is split into the parts that are cheapest to guess:
+// dictionary words, which cost the bits of their frequency, repeats of earlier
+// characters, sequences and keyboard walks, which cost the bits of their
+// start, direction and length, and single random letters, which cost
+// log2(26) bits each. The passphrase is rated the way WithPassphrase keys the
+// deck: every byte other than the letters A to Z, including lower-case
+// letters, is a count cut of zero, which adds no entropy. Rate the letters of
+// NormalizePassphrase for WithNormalizedPassphrase.
+//
+// The estimate is an upper bound on what a careful attacker needs: it
+// cannot know the words and habits of a particular person.
+func EstimateStrength(passphrase []byte) PassphraseStrength {
+	text := make([]rune, len(passphrase))
+	for i, b := range passphrase {
+		text[i] = rune(b)
+	}
+	result := estimateStrength(text, true)
+	for i, w := range result.Weaknesses {
+		// Each rune of the text stands for a byte of the passphrase.
+		result.Weaknesses[i].Text = string(passphrase[w.Position : w.Position+len([]rune(w.Text))])
+	}
+	return result
+}
+
+// EstimateTextStrength is like EstimateStrength for passphrases that are
+// stretched with WithKDFPassphrase, which uses every character. Single
+// random characters cost log2(26) bits for a letter, log2(10) for a digit
+// and more for other characters. Case is ignored, so the estimate is low
+// for passphrases of mixed case.
+func EstimateTextStrength(passphrase []byte) PassphraseStrength {
+	return estimateStrength([]rune(strings.ToUpper(string(passphrase))), false)
+}
+
+// estimateStrength estimates the entropy of the text. If zeroCuts is set,
+// characters other than A to Z add no entropy.
+func estimateStrength(text []rune, zeroCuts bool) PassphraseStrength {
+	n := len(text)
+
+	// best[j] is the fewest bits of a split of text[:j], whose last part
+	// starts at start[j] and is the weakness weak[j], if any.
+	best := make([]float64, n+1)
+	start := make([]int, n+1)
+	weak := make([]*PassphraseWeakness, n+1)
+	consider := func(i, j int, bits float64, w *PassphraseWeakness) {
+		if bits = best[i] + bits; bits < best[j] {
+			best[j], start[j], weak[j] = bits, i, w
+		}
+	}
+	for j := 1; j <= n; j++ {
+		best[j] = math.Inf(1)
+	}
+	maxWord := 0
+	dicts := Dictionaries()
+	for _, name := range dicts {
+		maxWord = max(maxWord, LookupDictionary(name).maxLen)
+	}
+	maxWord = min(maxWord, maxPatternLength)
+	// earlier maps the first characters of each part to the positions at
+	// which they occurred before, to find repeats without scanning all of them.
+	earlier := make(map[[minPatternLength]rune][]int)
+
+	// The parts starting at i are considered once the split of text[:i] is known.
+	for i := 0; i < n; i++ {
+		if r := text[i]; zeroCuts && !isLetter(r) {
+			consider(i, i+1, 0, &PassphraseWeakness{Kind: WeaknessZeroCut, Text: string(r), Position: i})
+			continue
+		}
+		consider(i, i+1, runeBits(text[i]), nil)
+		for j := i + 1; j <= min(n, i+maxWord) && isLetter(text[j-1]); j++ {
+			if w, bits, ok := dictionaryWord(dicts, text[i:j]); ok {
+				consider(i, j, bits, &PassphraseWeakness{Kind: WeaknessWord, Text: w, Position: i, Bits: bits})
+			}
+		}
+		for j := i + minPatternLength; j <= min(n, i+maxSequenceLength); j++ {
+			part := text[i:j]
+			kind, bits, ok := sequenceBits(part)
+			if !ok {
+				// A longer part is not a sequence either.
+				break
+			}
+			consider(i, j, bits, &PassphraseWeakness{Kind: kind, Text: string(part), Position: i, Bits: bits})
+		}
+		if i+minPatternLength > n {
+			continue
+		}
+		key := [minPatternLength]rune(text[i : i+minPatternLength])
+		positions := earlier[key]
+		length := 0
+		for _, p := range positions[max(0, len(positions)-maxRepeatCandidates):] {
+			l := minPatternLength
+			for i+l < n && l < maxPatternLength && text[i+l] == text[p+l] {
+				l++
+			}
+			length = max(length, l)
+		}
+		for j := i + minPatternLength; j <= i+length; j++ {
+			// The attacker guesses the distance to the earlier characters and the length.
+			bits := math.Log2(float64(i)) + math.Log2(float64(j-i))
+			consider(i, j, bits, &PassphraseWeakness{Kind: WeaknessRepeat, Text: string(text[i:j]), Position: i, Bits: bits})
+		}
+		earlier[key] = append(positions, i)
+	}
+
+	result := PassphraseStrength{Entropy: best[n], Weaknesses: make([]PassphraseWeakness, 0)}
+	for j := n; j > 0; j = start[j] {
+		w := weak[j]
+		if w == nil {
+			continue
+		}
+		// Join runs of zero cuts.
+		if last := len(result.Weaknesses) - 1; w.Kind == WeaknessZeroCut && last >= 0 &&
+			result.Weaknesses[last].Kind == WeaknessZeroCut && result.Weaknesses[last].Position == j {
+			result.Weaknesses[last].Text = w.Text + result.Weaknesses[last].Text
+			result.Weaknesses[last].Position = w.Position
+			continue
+		}
+		result.Weaknesses = append(result.Weaknesses, *w)
+	}
+	for i, j := 0, len(result.Weaknesses)-1; i < j; i, j = i+1, j-1 {
+		result.Weaknesses[i], result.Weaknesses[j] = result.Weaknesses[j], result.Weaknesses[i]
+	}
+	for l := StrengthFull; l >= StrengthWeak; l-- {
+		if result.Entropy >= l.Bits() {
+			result.Level = l
+			break
+		}
+	}
+	return result
+}
+
+func isLetter(r rune) bool {
+	return r >= 'A' && r <= 'Z'
+}
+
+// runeBits returns the bits of guessing a single character at random.
+func runeBits(r rune) float64 {
+	switch {
+	case isLetter(r):
+		return math.Log2(26)
+	case r >= '0' && r <= '9':
+		return math.Log2(10)
+	case r < unicode.MaxASCII:
+		// The printable ASCII characters besides letters and digits.
+		return math.Log2(33)
+	default:
+		// Letters of other alphabets and symbols, of which a passphrase
+		// typically uses a small script.
+		return math.Log2(100)
+	}
+}
+
+// dictionaryWord returns the bits of the letters as the most frequent word of
+// the dictionaries.
+func dictionaryWord(dicts []string, letters []rune) (string, float64, bool) {
+	w := string(letters)
+	bits, found := math.Inf(1), false
+	for _, name := range dicts {
+		if p, ok := LookupDictionary(name).logProb[w]; ok {
+			bits, found = min(bits, -p*math.Log2(10)), true
+		}
+	}
+	return w, bits, found
+}
+
+// sequenceBits reports whether part is a sequence or keyboard walk and
+// returns the bits of its start, direction and length.
+func sequenceBits(part []rune) (WeaknessKind, float64, bool) {
+	s := string(part)
+	reversed := []rune(s)
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+	for _, seq := range sequences {
+		if strings.Contains(seq.chars, s) || strings.Contains(seq.chars, string(reversed)) {
+			return seq.kind, math.Log2(float64(len(seq.chars))) + 1 + math.Log2(float64(len(part))), true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,127 @@
+package solitaire
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type StrengthSuite struct {
+	suite.Suite
+}
+
+func (s *StrengthSuite) TestRandomLetters() {
+	r := EstimateStrength([]byte("XKQJZVMWPLRTGHBNCDFSYEUOIA"))
+	s.InDelta(26*math.Log2(26), r.Entropy, 0.01)
+	s.Empty(r.Weaknesses)
+	s.Equal(StrengthGood, r.Level)
+
+	r = EstimateStrength([]byte(strings.Repeat("XKQJZVMWPLRTGHBNCDFSYEUOIA", 2)))
+	s.Equal(StrengthStrong, r.Level, "The repeat costs only a few bits")
+	s.Len(r.Weaknesses, 1)
+	s.Equal(WeaknessRepeat, r.Weaknesses[0].Kind)
+}
+
+func (s *StrengthSuite) TestEmpty() {
+	r := EstimateStrength(nil)
+	s.Zero(r.Entropy)
+	s.Equal(StrengthWeak, r.Level)
+	s.Empty(r.Weaknesses)
+}
+
+func (s *StrengthSuite) TestWords() {
+	r := EstimateStrength([]byte("CORRECTHORSEBATTERYSTAPLE"))
+	s.Equal(StrengthWeak, r.Level)
+	words := make([]string, 0)
+	for _, w := range r.Weaknesses {
+		if w.Kind == WeaknessWord {
+			words = append(words, w.Text)
+		}
+	}
+	s.Equal([]string{"CORRECT", "HORSE", "BATTERY", "STAPLE"}, words)
+	s.Less(r.Entropy, EstimateStrength([]byte("XKQJZVMWPLRTGHBNCDFSYEUO")).Entropy)
+}
+
+func (s *StrengthSuite) TestPatterns() {
+	for _, tc := range []struct {
+		passphrase string
+		kind       WeaknessKind
+		text       string
+	}{
+		{"AAAAAAAAAAAAAAAA", WeaknessRepeat, "AAAAAAAAAAAAAAA"},
+		{"abcdefgh", WeaknessSequence, "ABCDEFGH"},
+		{"ZYXWVU", WeaknessSequence, "ZYXWVU"},
+		{"87654321", WeaknessSequence, "87654321"},
+		{"qwertzuiop", WeaknessKeyboardWalk, "QWERTZUIOP"},
+		{"lkjhgfdsa", WeaknessKeyboardWalk, "LKJHGFDSA"},
+	} {
+		r := EstimateTextStrength([]byte(tc.passphrase))
+		s.Require().NotEmpty(r.Weaknesses, tc.passphrase)
+		w := r.Weaknesses[len(r.Weaknesses)-1]
+		s.Equal(tc.kind, w.Kind, tc.passphrase)
+		s.Equal(tc.text, w.Text, tc.passphrase)
+		s.Less(r.Entropy, 16.0, tc.passphrase)
+	}
+}
+
+func (s *StrengthSuite) TestUnicode() {
+	r := EstimateTextStrength([]byte("日本語のパスフレーズ"))
+	s.InDelta(10*math.Log2(100), r.Entropy, 0.01)
+	r = EstimateTextStrength([]byte("Grüße aus Köln"))
+	s.Require().Len(r.Weaknesses, 1)
+	s.Equal(PassphraseWeakness{Kind: WeaknessWord, Text: "AUS", Position: 6, Bits: r.Weaknesses[0].Bits}, r.Weaknesses[0])
+}
+
+func (s *StrengthSuite) TestZeroCuts() {
+	// Lower-case letters and other bytes key the deck as cuts of zero, so
+	// these passphrases key the same deck and have no entropy.
+	for _, p := range []string{"kemubcrdlsbqgbcnnchcrnbsdhuussbssmbhbrejnerdsjrvfdssuglrwcsb", "xkqjzvmwplrtghbncdfsyeuoiaxkqjzvmwplrtghbncdfsyeuoia"} {
+		r := EstimateStrength([]byte(p))
+		s.Zero(r.Entropy, p)
+		s.Equal(StrengthWeak, r.Level)
+		s.Equal([]PassphraseWeakness{{Kind: WeaknessZeroCut, Text: p}}, r.Weaknesses)
+	}
+
+	r := EstimateStrength([]byte("XKQJZ vmwpl 12 Grüße"))
+	s.InDelta(6*math.Log2(26), r.Entropy, 0.01, "Only XKQJZ and G count")
+	s.Equal([]PassphraseWeakness{
+		{Kind: WeaknessZeroCut, Text: " vmwpl 12 ", Position: 5},
+		{Kind: WeaknessZeroCut, Text: "rüße", Position: 16},
+	}, r.Weaknesses)
+	s.Greater(EstimateTextStrength([]byte("XKQJZ vmwpl 12 Grüße")).Entropy, r.Entropy)
+}
+
+func (s *StrengthSuite) TestLevels() {
+	r := EstimateStrength([]byte("KEMUBCRDLSBQGBCNNCHCRNBSDHUUSBSSMBHBREJNERDSJRVFDSSUGLDRWCSB"))
+	s.Greater(r.Entropy, DeckEntropy)
+	s.Equal(StrengthFull, r.Level)
+	s.Equal(1.0, r.Deck())
+	for l := StrengthWeak; l <= StrengthFull; l++ {
+		parsed, err := ParseStrengthLevel(strings.ToUpper(l.String()))
+		s.Require().NoError(err)
+		s.Equal(l, parsed)
+	}
+	_, err := ParseStrengthLevel("mediocre")
+	s.Error(err)
+}
+
+func TestStrength(t *testing.T) {
+	suite.Run(t, new(StrengthSuite))
+}
+
+func (s *StrengthSuite) TestLongPassphrase() {
+	// Long passphrases, e.g. from a file, are rated in linear time.
+	var b strings.Builder
+	for b.Len() < 20000 {
+		b.WriteString("KEMUBCRDLSBQGBCNNCHCRNBSDHUUSBSSMBHBREJNERDSJRVFDSSUGLDRWCSB")
+		b.WriteString(strings.Repeat("A", 100))
+		b.WriteString("THEQUICKBROWNFOXJUMPSOVERTHELAZYDOG")
+	}
+	start := time.Now()
+	r := EstimateStrength([]byte(b.String()))
+	s.Less(time.Since(start), 5*time.Second)
+	s.Equal(StrengthFull, r.Level)
+}
@@ -0,0 +1,121 @@
+package solitaire
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/awnumar/memguard"
+)
+
+// columnOrder returns the indices of the columns of a columnar transposition
+// in the order in which they are read: by the alphabetical order of the
+// keyword letters above them, equal letters from left to right.
+// The keyword is normalized like a cleartext and must have at least two letters.
+func columnOrder(keyword []byte) ([]int, error) {
+	letters := normalizeCleartext(keyword)
+	if len(letters) < 2 {
+		return nil, fmt.Errorf("transposition key must contain at least two letters")
+	}
+	order := make([]int, len(letters))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return letters[order[i]] < letters[order[j]]
+	})
+	return order, nil
+}
+
+// transpose writes the text in rows under the columns and reads it off column
+// by column in the given order. The last row may be incomplete, so the
+// columns are of irregular length.
+func transpose(text []byte, order []int) []byte {
+	out := make([]byte, 0, len(text))
+	for _, col := range order {
+		for i := col; i < len(text); i += len(order) {
+			out = append(out, text[i])
+		}
+	}
+	return out
+}
+
+// untranspose reverses transpose.
+func untranspose(text []byte, order []int) []byte {
+	out := make([]byte, len(text))
+	rows, long := len(text)/len(order), len(text)%len(order)
+	k := 0
+	for _, col := range order {
+		n := rows
+		if col < long {
+			n++
+		}
+		for r := 0; r < n; r++ {
+			out[col+r*len(order)] = text[k]
+			k++
+		}
+	}
+	return out
+}
+
+// WithDoubleTransposition adds a double columnar transposition on top of the
+// Solitaire encryption. Encrypt transposes the ciphertext letters with the
+// column order of the first keyword and then with the one of the second
+// keyword before splitting them into blocks of five; Decrypt reverses both
+// transpositions before decrypting.
+//
+// The keywords are secrets like the passphrase. The same keyword may be used
+// for both transpositions, but two different keywords of different lengths
+// are much stronger. The transposition cannot be combined with
+// WithPreservedFormat, and it spreads a letter dropped in transmission over
+// the whole message, so Repair does not work with it.
+func WithDoubleTransposition(first, second []byte) SolitaireOption {
+	return func(s *solitaire) error {
+		s.transpositions = nil
+		for _, keyword := range [][]byte{first, second} {
+			order, err := columnOrder(keyword)
+			if err != nil {
+				return err
+			}
+			s.transpositions = append(s.transpositions, order)
+		}
+		return nil
+	}
+}
+
+// WithDoubleTranspositionFromEnclaves is like WithDoubleTransposition, but
+// takes the keywords from memguard.Enclaves.
+// If an enclave cannot be opened, WithDoubleTranspositionFromEnclaves will panic.
+func WithDoubleTranspositionFromEnclaves(first, second *memguard.Enclave) SolitaireOption {
+	return func(s *solitaire) error {
+		if first == nil || second == nil {
+			return fmt.Errorf("transposition keys are required")
+		}
+		a, err := first.Open()
+		if err != nil {
+			memguard.SafePanic(err)
+		}
+		defer a.Destroy()
+		b, err := second.Open()
+		if err != nil {
+			memguard.SafePanic(err)
+		}
+		defer b.Destroy()
+		return WithDoubleTransposition(a.Bytes(), b.Bytes())(s)
+	}
+}
+
+// superencipher applies the transpositions to the letters of a ciphertext.
+func (s *solitaire) superencipher(ct []byte) []byte {
+	for _, order := range s.transpositions {
+		ct = transpose(ct, order)
+	}
+	return ct
+}
+
+// removeSuperencipherment reverses superencipher.
+func (s *solitaire) removeSuperencipherment(ct []byte) []byte {
+	for i := len(s.transpositions) - 1; i >= 0; i-- {
+		ct = untranspose(ct, s.transpositions[i])
+	}
+	return ct
+}
@@ -0,0 +1,84 @@
+package solitaire
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TranspositionSuite struct {
+	suite.Suite
+}
+
+func (s *TranspositionSuite) TestColumnOrder() {
+	order, err := columnOrder([]byte("zebras"))
+	s.NoError(err)
+	s.Equal([]int{4, 2, 1, 3, 5, 0}, order)
+
+	order, err = columnOrder([]byte("BAA"))
+	s.NoError(err)
+	s.Equal([]int{1, 2, 0}, order, "Equal letters are read from left to right")
+
+	for _, keyword := range []string{"", "A", "1 2 3"} {
+		_, err = columnOrder([]byte(keyword))
+		s.Error(err, keyword)
+	}
+}
+
+func (s *TranspositionSuite) TestTranspose() {
+	order, err := columnOrder([]byte("ZEBRAS"))
+	s.Require().NoError(err)
+	// The last row has a single letter, so the columns are of irregular length.
+	ct := transpose([]byte("WEAREDISCOVEREDFLEEATONCE"), order)
+	s.Equal("EVLNACDTESEAROFODEECWIREE", string(ct))
+	s.Equal("WEAREDISCOVEREDFLEEATONCE", string(untranspose(ct, order)))
+}
+
+func (s *TranspositionSuite) TestUntranspose() {
+	order, err := columnOrder([]byte("SECRET"))
+	s.Require().NoError(err)
+	text := []byte("ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	for n := 0; n <= len(text); n++ {
+		s.Equal(string(text[:n]), string(untranspose(transpose(text[:n], order), order)))
+	}
+}
+
+func (s *TranspositionSuite) TestDoubleTransposition() {
+	opts := []SolitaireOption{
+		WithPassphrase([]byte("CRYPTONOMICON")),
+		WithDoubleTransposition([]byte("ZEBRAS"), []byte("WINDOW")),
+	}
+	sol, err := New(opts...)
+	s.Require().NoError(err)
+	ct, err := sol.Encrypt([]byte("SOLITAIRE"))
+	s.Require().NoError(err)
+
+	plain, err := New(WithPassphrase([]byte("CRYPTONOMICON")))
+	s.Require().NoError(err)
+	expected, err := plain.Encrypt([]byte("SOLITAIRE"))
+	s.Require().NoError(err)
+	s.NotEqual(expected, ct)
+	s.ElementsMatch(expected, ct, "The transposition only reorders the letters")
+
+	sol, err = New(opts...)
+	s.Require().NoError(err)
+	pt, err := sol.Decrypt(ct)
+	s.Require().NoError(err)
+	s.Equal("SOLIT AIREX", string(pt))
+}
+
+func (s *TranspositionSuite) TestDoubleTranspositionInvalid() {
+	_, err := New(WithPassphrase([]byte("CRYPTONOMICON")), WithDoubleTransposition([]byte("ZEBRAS"), []byte("1")))
+	s.Error(err)
+	_, err = New(WithPassphrase([]byte("CRYPTONOMICON")), WithDoubleTransposition([]byte("ZEBRAS"), []byte("WINDOW")), WithPreservedFormat())
+	s.Error(err)
+
+	sol, err := New(WithPassphrase([]byte("CRYPTONOMICON")), WithDoubleTransposition([]byte("ZEBRAS"), []byte("WINDOW")))
+	s.Require().NoError(err)
+	_, err = sol.Repair([]byte("KIRAK SFJAN"), 1)
+	s.Error(err)
+}
+
+func TestTransposition(t *testing.T) {
+	suite.Run(t, new(TranspositionSuite))
+}